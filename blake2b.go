@@ -0,0 +1,15 @@
+package main
+
+import "golang.org/x/crypto/blake2b"
+
+// Blake2b256 calculates BLAKE2b-256 hash value of a byte slice (32-byte output).
+func Blake2b256(input []byte) []byte {
+	// blake2b.Sum256 is unkeyed so, unlike blake2b.New256, it has no error to handle
+	sum := blake2b.Sum256(input)
+	return sum[:]
+}
+
+func init() {
+	RegisterHash("blake2b-256", Blake2b256)
+	builtinDigestSizes[funcPointer(Blake2b256)] = blake2b.Size256
+}
@@ -0,0 +1,18 @@
+package main
+
+import "testing"
+
+func TestBlake2b256(t *testing.T) {
+	testHashVectors(t, "Blake2b256", Blake2b256, 32, []hashVector{
+		{"", "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8"},
+		{"abc", "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319"},
+	})
+}
+
+func BenchmarkBlake2b256(b *testing.B) {
+	benchmarkHashFuncs(b, 4096,
+		namedHashFunc{"Blake2b256", Blake2b256},
+		namedHashFunc{"Md5", Md5},
+		namedHashFunc{"Sha256", Sha256},
+	)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+
+	"lukechampine.com/blake3"
+)
+
+// Blake3_256 calculates BLAKE3 hash value of a byte slice (32-byte output).
+func Blake3_256(input []byte) []byte {
+	digest := blake3.Sum256(input)
+	return digest[:]
+}
+
+// NewBlake3Func builds a HashFunc that calculates BLAKE3 hash values of outLen bytes. BLAKE3 is an extendable-output
+// function: shorter outputs are prefixes of longer ones. It panics if outLen is not positive.
+func NewBlake3Func(outLen int) HashFunc {
+	if outLen <= 0 {
+		panic(fmt.Sprintf("checksum: can not build a %d-byte BLAKE3 hash", outLen))
+	}
+	return func(input []byte) []byte {
+		hf := blake3.New(outLen, nil)
+		hf.Write(input)
+		return hf.Sum(nil)
+	}
+}
+
+func init() {
+	RegisterHash("blake3-256", Blake3_256)
+	builtinDigestSizes[funcPointer(Blake3_256)] = 32
+}
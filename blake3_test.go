@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestBlake3(t *testing.T) {
+	// from the official test vectors: the input of length n is the sequence of bytes i%251 for i < n
+	input := make([]byte, 1024)
+	for i := range input {
+		input[i] = byte(i % 251)
+	}
+	testCases := []struct {
+		name     string
+		hf       HashFunc
+		input    []byte
+		expected string
+	}{
+		{"Blake3_256, empty", Blake3_256, nil, "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+		{"Blake3_256, 1024 bytes", Blake3_256, input, "42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af7"},
+		{"NewBlake3Func(32)", NewBlake3Func(32), input, "42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af7"},
+		{"NewBlake3Func(64), empty", NewBlake3Func(64), nil, "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262" +
+			"e00f03e7b69af26b7faaf09fcd333050338ddfe085b8cc869ca98b206c08243a"},
+		{"NewBlake3Func(8), empty", NewBlake3Func(8), nil, "af1349b9f5f9a1a6"},
+	}
+	for _, tc := range testCases {
+		if actual := hex.EncodeToString(tc.hf(tc.input)); actual != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, actual)
+		}
+	}
+	expectPanic(t, "NewBlake3Func(0)", func() { NewBlake3Func(0) })
+}
+
+func BenchmarkBlake3(b *testing.B) {
+	benchmarkHashFuncs(b, 64*1024,
+		namedHashFunc{"Blake3_256", Blake3_256},
+		namedHashFunc{"Sha256", Sha256},
+		namedHashFunc{"Blake2b256", Blake2b256},
+	)
+}
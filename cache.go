@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Cache remembers the last checksum seen for each key, e.g. to invalidate cached data derived from values that
+// changed. It is safe for concurrent use.
+type Cache struct {
+	hf        HashFunc
+	lock      sync.Mutex
+	checksums map[string][]byte
+}
+
+// NewCache creates a new empty Cache using the specified hash function.
+func NewCache(hf HashFunc) *Cache {
+	return &Cache{hf: hf, checksums: make(map[string][]byte)}
+}
+
+// HasChanged calculates the checksum of v, stores it for key, and tells whether it differs from the checksum previously
+// stored for key. It returns true the first time a key is seen, and whenever v can not be checksummed.
+func (c *Cache) HasChanged(key string, v interface{}) bool {
+	checksum := Checksum(c.hf, v)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	old, ok := c.checksums[key]
+	c.checksums[key] = checksum
+	return !ok || checksum == nil || !bytes.Equal(old, checksum)
+}
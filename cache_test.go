@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	cache := NewCache(Md5)
+	steps := []struct {
+		key      string
+		value    interface{}
+		expected bool
+	}{
+		{"a", map[string]int{"x": 1}, true},
+		{"a", map[string]int{"x": 1}, false},
+		{"b", map[string]int{"x": 1}, true},
+		{"a", map[string]int{"x": 2}, true},
+		{"a", map[string]int{"x": 2}, false},
+		{"a", map[string]int{"x": 1}, true},
+		{"b", map[string]int{"x": 1}, false},
+		{"c", make(chan int), true},
+		{"c", make(chan int), true},
+	}
+	for i, step := range steps {
+		if actual := cache.HasChanged(step.key, step.value); actual != step.expected {
+			t.Errorf("step %d (%s=%v): expected %v, got %v", i, step.key, step.value, step.expected, actual)
+		}
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	cache := NewCache(Md5)
+	const goroutines, keys = 8, 50
+	var wg sync.WaitGroup
+	changes := make([]int, goroutines)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for round := 0; round < 3; round++ {
+				for k := 0; k < keys; k++ {
+					if cache.HasChanged(fmt.Sprintf("key-%d", k), k) {
+						changes[g]++
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	// values never change, so each key is reported as changed exactly once, by whichever goroutine saw it first
+	total := 0
+	for _, n := range changes {
+		total += n
+	}
+	if total != keys {
+		t.Errorf("expected %d changes, got %d", keys, total)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"hash/crc32"
+
+	"golang.org/x/sys/cpu"
+)
+
+// Crc32FastAlgorithm is the registered name of the CRC32 variant Crc32Fast uses on this machine: "crc32c" (see Crc32C)
+// if the CPU accelerates the Castagnoli polynomial in hardware (SSE4.2 on amd64, the CRC32 extension on arm64),
+// "crc32" (see Crc32) otherwise. It is informational only: the polynomial is chosen once at init, so changing the
+// variable does not change what Crc32Fast computes.
+var Crc32FastAlgorithm = crc32FastAlgorithm()
+
+var crc32FastTable = crc32FastTableFor(Crc32FastAlgorithm)
+
+func crc32FastAlgorithm() string {
+	if cpu.X86.HasSSE42 || cpu.ARM64.HasCRC32 {
+		return "crc32c"
+	}
+	return "crc32"
+}
+
+func crc32FastTableFor(algorithm string) *crc32.Table {
+	if algorithm == "crc32c" {
+		return crc32.MakeTable(crc32.Castagnoli)
+	}
+	return crc32.IEEETable
+}
+
+// Crc32Fast calculates CRC32 hash value of a byte slice, using the Castagnoli polynomial if the CPU accelerates it in
+// hardware (see Crc32FastAlgorithm). Note that on amd64 the IEEE polynomial is accelerated too (via PCLMULQDQ), and
+// may be just as fast: see BenchmarkCrc32Fast.
+//
+// Crc32Fast optimizes speed at the cost of portability: the same input may have different checksums on different
+// machines. Store Crc32FastAlgorithm along with the checksums (see Result), or use Crc32 or Crc32C if checksums are
+// compared across machines.
+func Crc32Fast(input []byte) []byte {
+	hf := crc32.New(crc32FastTable)
+	hf.Write(input)
+	return hf.Sum(nil)
+}
+
+func init() {
+	builtinDigestSizes[funcPointer(Crc32Fast)] = crc32.Size
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCrc32Fast(t *testing.T) {
+	name := Crc32FastAlgorithm
+	hf, ok := GetHash(name)
+	if !ok || (name != "crc32" && name != "crc32c") {
+		t.Fatalf("expected crc32 or crc32c, got %q", name)
+	}
+	for _, input := range []string{"", "123456789", "The quick brown fox jumps over the lazy dog"} {
+		if expected, actual := hf([]byte(input)), Crc32Fast([]byte(input)); !bytes.Equal(actual, expected) {
+			t.Errorf("%q: expected %x (%s), got %x", input, expected, name, actual)
+		}
+	}
+	if size := DigestSize(Crc32Fast); size != 4 {
+		t.Errorf("expected a digest size of 4, got %d", size)
+	}
+}
+
+func BenchmarkCrc32Fast(b *testing.B) {
+	benchmarkHashFuncs(b, 64*1024,
+		namedHashFunc{"Crc32Fast", Crc32Fast},
+		namedHashFunc{"Crc32C", Crc32C},
+		namedHashFunc{"Crc32", Crc32},
+	)
+}
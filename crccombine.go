@@ -0,0 +1,78 @@
+package main
+
+import (
+	"hash/crc32"
+	"hash/crc64"
+)
+
+// Crc32Combine calculates the CRC32 (IEEE polynomial, see Crc32) of the concatenation of two blocks of data A and B,
+// given crc1 (the CRC32 of A), crc2 (the CRC32 of B) and len2 (the length of B), without access to the data: e.g. to
+// checksum chunks of a large input in parallel. CRC values are as returned by crc32.ChecksumIEEE, i.e. Crc32's output
+// read as a big-endian uint32.
+func Crc32Combine(crc1, crc2 uint32, len2 int64) uint32 {
+	return uint32(crcCombine(crc32.IEEE, 32, uint64(crc1), uint64(crc2), len2))
+}
+
+// Crc64Combine is similar to Crc32Combine, for CRC64 checksums using the ISO polynomial (see Crc64), as returned by
+// crc64.Checksum with a crc64.ISO table.
+func Crc64Combine(crc1, crc2 uint64, len2 int64) uint64 {
+	return crcCombine(crc64.ISO, 64, crc1, crc2, len2)
+}
+
+// crcCombine combines two CRCs of a reversed polynomial of the specified width, the way zlib's crc32_combine does:
+// appending len2 bytes to A amounts to multiplying crc1 by x^(8*len2) modulo the polynomial, which is done by
+// repeatedly squaring the GF(2) matrix of the "append one zero bit" operator.
+func crcCombine(poly uint64, width int, crc1, crc2 uint64, len2 int64) uint64 {
+	if len2 <= 0 {
+		return crc1
+	}
+	even := make([]uint64, width) // operator for an even power of two zero bits
+	odd := make([]uint64, width)  // operator for an odd power of two zero bits
+
+	// operator for one zero bit
+	odd[0] = poly
+	row := uint64(1)
+	for n := 1; n < width; n++ {
+		odd[n] = row
+		row <<= 1
+	}
+	gf2MatrixSquare(even, odd) // two zero bits
+	gf2MatrixSquare(odd, even) // four zero bits
+
+	// apply len2 zero bytes to crc1, the first squaring giving the operator for one zero byte (eight zero bits)
+	for {
+		gf2MatrixSquare(even, odd)
+		if len2&1 != 0 {
+			crc1 = gf2MatrixTimes(even, crc1)
+		}
+		if len2 >>= 1; len2 == 0 {
+			break
+		}
+		gf2MatrixSquare(odd, even)
+		if len2&1 != 0 {
+			crc1 = gf2MatrixTimes(odd, crc1)
+		}
+		if len2 >>= 1; len2 == 0 {
+			break
+		}
+	}
+	return crc1 ^ crc2
+}
+
+// gf2MatrixTimes multiplies a GF(2) matrix, given as its columns, by a vector.
+func gf2MatrixTimes(mat []uint64, vec uint64) uint64 {
+	var sum uint64
+	for i := 0; vec != 0; i, vec = i+1, vec>>1 {
+		if vec&1 != 0 {
+			sum ^= mat[i]
+		}
+	}
+	return sum
+}
+
+// gf2MatrixSquare stores the square of a GF(2) matrix into square.
+func gf2MatrixSquare(square, mat []uint64) {
+	for n := range mat {
+		square[n] = gf2MatrixTimes(mat, mat[n])
+	}
+}
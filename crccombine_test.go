@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"hash/crc64"
+	"math/rand"
+	"testing"
+)
+
+func TestCrcCombine(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	data := make([]byte, 5000)
+	r.Read(data)
+	isoTable := crc64.MakeTable(crc64.ISO)
+	for _, n := range []int{0, 1, 2, 7, 64, 1000, 5000} {
+		input := data[:n]
+		for _, split := range []int{0, 1, n / 3, n / 2, n - 1, n} {
+			if split < 0 || split > n {
+				continue
+			}
+			a, b := input[:split], input[split:]
+			if expected, actual := crc32.ChecksumIEEE(input), Crc32Combine(crc32.ChecksumIEEE(a), crc32.ChecksumIEEE(b), int64(len(b))); actual != expected {
+				t.Errorf("Crc32Combine %d/%d: expected %08x, got %08x", split, n-split, expected, actual)
+			}
+			if expected, actual := crc64.Checksum(input, isoTable), Crc64Combine(crc64.Checksum(a, isoTable), crc64.Checksum(b, isoTable), int64(len(b))); actual != expected {
+				t.Errorf("Crc64Combine %d/%d: expected %016x, got %016x", split, n-split, expected, actual)
+			}
+		}
+	}
+	// combining many chunks checksummed independently
+	var crc uint32
+	for chunks := data; len(chunks) > 0; {
+		chunk := chunks
+		if len(chunk) > 333 {
+			chunk = chunk[:333]
+		}
+		chunks = chunks[len(chunk):]
+		crc = Crc32Combine(crc, crc32.ChecksumIEEE(chunk), int64(len(chunk)))
+	}
+	if expected := crc32.ChecksumIEEE(data); crc != expected {
+		t.Errorf("Crc32Combine of chunks: expected %08x, got %08x", expected, crc)
+	}
+	// consistent with Crc32's output
+	if binary.BigEndian.Uint32(Crc32(data)) != crc32.ChecksumIEEE(data) {
+		t.Errorf("expected Crc32 to be the big-endian form of crc32.ChecksumIEEE")
+	}
+	if binary.BigEndian.Uint64(Crc64(data)) != crc64.Checksum(data, isoTable) {
+		t.Errorf("expected Crc64 to be the big-endian form of crc64.Checksum")
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+)
+
+// Diff checksums the corresponding values nested inside a and b, and returns the sorted paths of the innermost values
+// whose checksums differ, e.g. []string{"Addr.Zip", "Items[2]"}, in the format of ChecksumError.Path. Values present
+// in only one of a and b (e.g. extra slice elements or map entries) are reported as well; the empty path denotes the
+// top-level value. It returns an empty slice if a and b have the same checksum.
+func Diff(hf HashFunc, a, b interface{}) ([]string, error) {
+	digestsA, err := pathDigests(hf, a)
+	if err != nil {
+		return nil, err
+	}
+	digestsB, err := pathDigests(hf, b)
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for path, digest := range digestsA {
+		if !bytes.Equal(digest, digestsB[path]) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range digestsB {
+		if _, ok := digestsA[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	result := []string{}
+	for _, path := range changed {
+		if !hasNestedPath(changed, path) {
+			result = append(result, path)
+		}
+	}
+	return result, nil
+}
+
+// pathDigests traces the checksum calculation of a value and returns the checksum of each value nested inside it,
+// keyed by path. The checksum of a value is the last one traced under its path, after those of its field name or map
+// key.
+func pathDigests(hf HashFunc, v interface{}) (map[string][]byte, error) {
+	w := newWalker(context.Background(), hf, nil)
+	w.tracing = true
+	if _, err := w.checksum(v); err != nil {
+		return nil, err
+	}
+	digests := make(map[string][]byte, len(w.trace))
+	for _, entry := range w.trace {
+		digests[entry.Path] = entry.Digest
+	}
+	return digests, nil
+}
+
+// hasNestedPath tells whether any of paths denotes a value nested inside the value denoted by parent.
+func hasNestedPath(paths []string, parent string) bool {
+	for _, path := range paths {
+		if len(path) <= len(parent) || !strings.HasPrefix(path, parent) {
+			continue
+		}
+		if parent == "" || path[len(parent)] == '.' || path[len(parent)] == '[' {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type address struct {
+		Street, Zip string
+	}
+	type order struct {
+		ID    int
+		Addr  address
+		Items []string
+		Tags  map[string]int
+	}
+	base := order{ID: 1, Addr: address{"Main St", "10001"}, Items: []string{"a", "b", "c"}, Tags: map[string]int{"x": 1}}
+	testCases := []struct {
+		name     string
+		a, b     interface{}
+		expected []string
+	}{
+		{"equal", base, base, []string{}},
+		{"nested field", base, order{ID: 1, Addr: address{"Main St", "10002"}, Items: []string{"a", "b", "c"},
+			Tags: map[string]int{"x": 1}}, []string{"Addr.Zip"}},
+		{"slice element", base, order{ID: 1, Addr: base.Addr, Items: []string{"a", "b", "d"}, Tags: base.Tags},
+			[]string{"Items[2]"}},
+		{"extra map entry", base, order{ID: 1, Addr: base.Addr, Items: base.Items, Tags: map[string]int{"x": 1, "y": 2}},
+			[]string{`Tags["y"]`}},
+		{"top-level", 1, 2, []string{""}},
+		{"int and string keys", map[interface{}]int{1: 1, "1": 2}, map[interface{}]int{1: 1, "1": 3},
+			[]string{`[string("1")]`}},
+		{"int and string keys swapped", map[interface{}]int{1: 2, "1": 1}, map[interface{}]int{1: 3, "1": 1},
+			[]string{`[int(1)]`}},
+	}
+	for _, tc := range testCases {
+		actual, err := Diff(Md5, tc.a, tc.b)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+		} else if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// ChecksumFields calculates checksums of the fields of a struct (or pointer to struct) individually, e.g. to detect
+// which fields of a record changed. The result maps field names, as mixed into the struct's checksum (i.e. honoring
+// `checksum:"name"` tags), to the checksum of the field's value; fields excluded from the struct's checksum are
+// excluded from the result.
+func ChecksumFields(hf HashFunc, v interface{}) (map[string][]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, &ChecksumError{Err: fmt.Errorf("expected a struct, got %s", rv.Kind())}
+	}
+	w := newWalker(context.Background(), hf, nil)
+	fields, err := cachedStructFields(rv.Type(), w.cfg.fields)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string][]byte, len(fields))
+	for _, field := range fields {
+		temp, err := w.checksum(fieldValue(rv, field))
+		if err != nil {
+			return nil, withPath(err, "."+field.goName)
+		}
+		result[field.name] = temp
+	}
+	return result, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestChecksumFields(t *testing.T) {
+	type user struct {
+		ID      int `checksum:"id"`
+		Name    string
+		Tags    []string
+		Cache   string `checksum:"-"`
+		private int
+	}
+	u := user{ID: 1, Name: "n", Tags: []string{"a"}, Cache: "c", private: 2}
+	fields, err := ChecksumFields(Md5, &u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"id": u.ID, "Name": u.Name, "Tags": u.Tags}
+	if len(fields) != len(expected) {
+		t.Errorf("expected %d fields, got %d: %x", len(expected), len(fields), fields)
+	}
+	for name, value := range expected {
+		if actual := fields[name]; !bytes.Equal(actual, Checksum(Md5, value)) {
+			t.Errorf("%s: expected %x, got %x", name, Checksum(Md5, value), actual)
+		}
+	}
+
+	u.Name = "changed"
+	changed, _ := ChecksumFields(Md5, u)
+	for name := range expected {
+		if same := bytes.Equal(fields[name], changed[name]); same != (name != "Name") {
+			t.Errorf("%s: expected only the changed field's checksum to change", name)
+		}
+	}
+
+	if _, err := ChecksumFields(Md5, []int{1}); err == nil {
+		t.Errorf("expected an error for a non-struct value")
+	}
+}
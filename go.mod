@@ -1,3 +1,14 @@
-module main
+module github.com/btnguyen2k/demo-go-checksum
 
 go 1.19
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0
+	github.com/spaolacci/murmur3 v1.1.0
+	golang.org/x/crypto v0.17.0
+	golang.org/x/sys v0.15.0
+	golang.org/x/text v0.14.0
+	lukechampine.com/blake3 v1.1.7
+)
+
+require github.com/klauspost/cpuid/v2 v2.0.9 // indirect
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// ChecksumGob calculates checksum of a value's encoding/gob encoding, as a fast way to tell whether the exact same Go
+// value changed. Unlike Checksum, the result is not canonical:
+//   - it depends on the Go types involved, e.g. changing a field's type or the name of a struct changes it
+//   - gob encodes maps in iteration order, so values containing maps with more than one entry do not have a stable
+//     checksum
+//   - gob rules apply: e.g. unexported fields are ignored, and interface values must have been registered via
+//     gob.Register
+func ChecksumGob(hf HashFunc, v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return hf(buf.Bytes()), nil
+}
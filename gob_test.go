@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+	"time"
+)
+
+type gobItem struct {
+	SKU      string
+	Quantity int
+	Price    float64
+}
+
+type gobOrder struct {
+	ID       int64
+	Customer string
+	Created  time.Time
+	Items    []gobItem
+	Notes    []string
+}
+
+func testGobOrder() gobOrder {
+	order := gobOrder{ID: 42, Customer: "alice", Created: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), Notes: []string{"fragile"}}
+	for i := 0; i < 20; i++ {
+		order.Items = append(order.Items, gobItem{SKU: "sku-" + string(rune('a'+i)), Quantity: i, Price: float64(i) * 1.5})
+	}
+	return order
+}
+
+func TestChecksumGob(t *testing.T) {
+	order := testGobOrder()
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(order); err != nil {
+		t.Fatal(err)
+	}
+	expected := Md5(buf.Bytes())
+	for i := 0; i < 3; i++ {
+		if actual, err := ChecksumGob(Md5, order); err != nil || !bytes.Equal(actual, expected) {
+			t.Errorf("expected %x, got %x (%v)", expected, actual, err)
+		}
+	}
+	changed := testGobOrder()
+	changed.Items[10].Quantity++
+	if actual, _ := ChecksumGob(Md5, changed); bytes.Equal(actual, expected) {
+		t.Errorf("expected changing a nested field to change the checksum")
+	}
+	if _, err := ChecksumGob(Md5, struct{ C chan int }{make(chan int)}); err == nil {
+		t.Errorf("expected an error for a value gob can not encode")
+	}
+}
+
+func BenchmarkChecksumGob(b *testing.B) {
+	order := testGobOrder()
+	b.Run("ChecksumGob", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ChecksumGob(Sha256, order)
+		}
+	})
+	b.Run("Checksum", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Checksum(Sha256, order)
+		}
+	})
+}
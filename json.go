@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// ChecksumJSON calculates checksum of a value's canonical JSON representation: object keys sorted, no insignificant
+// whitespace, no HTML escaping, and numbers kept exactly as encoding/json formats them. This makes the checksum
+// independent of Go types, and reproducible by non-Go services that hash canonical JSON.
+func ChecksumJSON(hf HashFunc, v interface{}) ([]byte, error) {
+	data, err := canonicalJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	return hf(data), nil
+}
+
+// canonicalJSON marshals a value to JSON, then round-trips the result through generic maps so that struct fields get
+// sorted like map keys.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(generic); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestChecksumJSON(t *testing.T) {
+	a := map[string]interface{}{}
+	a["x"], a["y"], a["z"] = 1, "two", []int{3}
+	b := map[string]interface{}{}
+	b["z"], b["y"], b["x"] = []int{3}, "two", 1
+	ca, errA := ChecksumJSON(Sha256, a)
+	cb, errB := ChecksumJSON(Sha256, b)
+	if errA != nil || errB != nil || !bytes.Equal(ca, cb) {
+		t.Errorf("expected insertion order not to matter, got %x and %x (%v, %v)", ca, cb, errA, errB)
+	}
+
+	// reference digest: Python's hashlib.sha256 over json.dumps(v, sort_keys=True, separators=(",", ":"),
+	// ensure_ascii=False)
+	type nested struct {
+		Z interface{} `json:"z"`
+		A bool        `json:"a"`
+	}
+	type document struct {
+		Name   string   `json:"name"`
+		ID     int      `json:"id"`
+		Tags   []string `json:"tags"`
+		Nested nested   `json:"nested"`
+	}
+	expected := "34f7a397f4f65e7de52e41d8f89c1091bdc111a2e86c62fae40edbfea7bc4d1d"
+	actual, err := ChecksumJSON(Sha256, document{Name: "x<y", ID: 1, Tags: []string{"b", "a"}, Nested: nested{A: true}})
+	if err != nil || hex.EncodeToString(actual) != expected {
+		t.Errorf("expected %s, got %x (%v)", expected, actual, err)
+	}
+
+	if actual, err := ChecksumJSON(Sha256, map[string]interface{}{"c": make(chan int)}); actual != nil || err == nil {
+		t.Errorf("expected the marshal error to be returned, got %x, %v", actual, err)
+	}
+}
+
+func TestChecksumJSONNumber(t *testing.T) {
+	equal := [][]json.Number{
+		{"1", "1.0", "1e0", "10e-1", "0.1e1", "1.000"},
+		{"-0.5", "-5e-1", "-0.50"},
+		{"0", "0.0", "-0", "0e10"},
+		{"123456789012345678901234567890", "1.2345678901234567890123456789e29"},
+	}
+	var previous []byte
+	for _, group := range equal {
+		expected, err := ChecksumE(Md5, group[0])
+		if err != nil {
+			t.Fatalf("%s: %s", group[0], err)
+		}
+		for _, n := range group[1:] {
+			if actual, err := ChecksumE(Md5, n); err != nil || !bytes.Equal(actual, expected) {
+				t.Errorf("%s: expected %x (%s), got %x (%v)", n, expected, group[0], actual, err)
+			}
+		}
+		if bytes.Equal(expected, previous) {
+			t.Errorf("%s: expected a different checksum than the previous group", group[0])
+		}
+		previous = expected
+	}
+	// decoded with UseNumber, and with the same value as a big.Rat
+	decoder := json.NewDecoder(strings.NewReader(`{"price": 12.50}`))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := Checksum(Md5, doc), Checksum(Md5, map[string]interface{}{"price": json.Number("12.5")}); a == nil || !bytes.Equal(a, b) {
+		t.Errorf("expected %x, got %x", b, a)
+	}
+	if a, b := Checksum(Md5, json.Number("0.25")), Checksum(Md5, big.NewRat(1, 4)); !bytes.Equal(a, b) {
+		t.Errorf("expected a json.Number to match the equal big.Rat: expected %x, got %x", b, a)
+	}
+	if a, b := Checksum(Md5, json.Number("1")), Checksum(Md5, "1"); bytes.Equal(a, b) {
+		t.Errorf("expected a json.Number to differ from the string, both got %x", a)
+	}
+	for _, garbage := range []json.Number{"", "abc", "1.2.3", "+1", "0x10", "1e", "NaN", "1e100000"} {
+		var ce *ChecksumError
+		if _, err := ChecksumE(Md5, garbage); !errors.As(err, &ce) {
+			t.Errorf("%q: expected a *ChecksumError, got %v", garbage, err)
+		}
+		if _, err := ChecksumE(Md5, []interface{}{1, garbage}); !errors.As(err, &ce) || ce.Path != "[1]" {
+			t.Errorf("%q: expected a *ChecksumError at [1], got %v", garbage, err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"hash"
+)
+
+// NewHmacFunc builds a HashFunc that calculates HMAC of a byte slice using the specified hash constructor (e.g.
+// sha256.New) and key.
+//
+// Only cryptographic hash functions (SHA-2, SHA-3, BLAKE2...) should be used as the base of HMAC; HMAC over a CRC or
+// FNV does not provide any protection against forgery.
+func NewHmacFunc(h func() hash.Hash, key []byte) HashFunc {
+	key = append([]byte(nil), key...)
+	return func(input []byte) []byte {
+		hf := hmac.New(h, key)
+		hf.Write(input)
+		return hf.Sum(nil)
+	}
+}
+
+// HmacSha256 builds a HashFunc that calculates HMAC-SHA256 of a byte slice using the specified key (32-byte output).
+// It is equivalent to NewHmacFunc(sha256.New, key).
+func HmacSha256(key []byte) HashFunc {
+	return NewHmacFunc(sha256.New, key)
+}
+
+// WithSalt builds a HashFunc that prepends a fixed salt to every input before delegating to hf, e.g. to domain-separate
+// checksums of the same value calculated for different purposes.
+//
+// When used with Checksum, every hash calculated during the walk (nested elements included) is salted.
+func WithSalt(hf HashFunc, salt []byte) HashFunc {
+	salt = append([]byte(nil), salt...)
+	return func(input []byte) []byte {
+		return hf(append(salt[:len(salt):len(salt)], input...))
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+)
+
+func TestNewHmacFunc(t *testing.T) {
+	// RFC 4231, test case 2
+	key, message := []byte("Jefe"), []byte("what do ya want for nothing?")
+	expected := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if actual := hex.EncodeToString(HmacSha256(key)(message)); actual != expected {
+		t.Errorf("HmacSha256: expected %s, got %s", expected, actual)
+	}
+	mac := hmac.New(sha512.New, key)
+	mac.Write(message)
+	if actual := NewHmacFunc(sha512.New, key)(message); !bytes.Equal(actual, mac.Sum(nil)) {
+		t.Errorf("NewHmacFunc(sha512.New): expected %x, got %x", mac.Sum(nil), actual)
+	}
+
+	type record struct {
+		ID   int
+		Tags []string
+	}
+	v := record{1, []string{"a", "b"}}
+	a, b := Checksum(NewHmacFunc(sha256.New, key), v), Checksum(NewHmacFunc(sha256.New, key), v)
+	if a == nil || !bytes.Equal(a, b) {
+		t.Errorf("expected a stable checksum, got %x and %x", a, b)
+	}
+	if c := Checksum(NewHmacFunc(sha256.New, []byte("other key")), v); bytes.Equal(a, c) {
+		t.Errorf("expected the checksum to depend on the key, both got %x", a)
+	}
+	// the key is copied
+	mutable := []byte("Jefe")
+	hf := HmacSha256(mutable)
+	mutable[0] = 'X'
+	if actual := hex.EncodeToString(hf(message)); actual != expected {
+		t.Errorf("expected the key to be copied, got %s", actual)
+	}
+}
+
+func TestWithSalt(t *testing.T) {
+	salt := []byte("cache:")
+	v := map[string][]int{"a": {1, 2}, "b": {3}}
+	salted, unsalted := Checksum(WithSalt(Md5, salt), v), Checksum(Md5, v)
+	if salted == nil || bytes.Equal(salted, unsalted) {
+		t.Errorf("expected salted and unsalted checksums to differ, got %x and %x", salted, unsalted)
+	}
+	if again := Checksum(WithSalt(Md5, []byte("cache:")), v); !bytes.Equal(salted, again) {
+		t.Errorf("expected the same salt to be deterministic, got %x and %x", salted, again)
+	}
+	if other := Checksum(WithSalt(Md5, []byte("etag:")), v); bytes.Equal(salted, other) {
+		t.Errorf("expected different salts to give different checksums, both got %x", salted)
+	}
+	if actual, expected := WithSalt(Md5, salt)([]byte("abc")), Md5([]byte("cache:abc")); !bytes.Equal(actual, expected) {
+		t.Errorf("expected the salt to be prepended, got %x instead of %x", actual, expected)
+	}
+
+	// every hash calculated during the walk is salted
+	calls := 0
+	spy := func(input []byte) []byte {
+		calls++
+		if !bytes.HasPrefix(input, salt) {
+			t.Errorf("expected input %x to start with the salt", input)
+		}
+		return Md5(input)
+	}
+	Checksum(WithSalt(spy, salt), v)
+	if calls < 2 {
+		t.Errorf("expected nested elements to be hashed with the salt, got %d calls", calls)
+	}
+}
+
+func TestHmacSha256(t *testing.T) {
+	key, message := []byte("secret key"), []byte("message to authenticate")
+	mac := hmac.New(sha256.New, key)
+	mac.Write(message)
+	if actual := HmacSha256(key)(message); !bytes.Equal(actual, mac.Sum(nil)) {
+		t.Errorf("expected %x, got %x", mac.Sum(nil), actual)
+	}
+	type record struct {
+		ID   int
+		Name string
+	}
+	a, b := Checksum(HmacSha256(key), record{1, "n"}), Checksum(NewHmacFunc(sha256.New, key), record{1, "n"})
+	if len(a) != sha256.Size || !bytes.Equal(a, b) {
+		t.Errorf("expected HmacSha256 to be equivalent to NewHmacFunc(sha256.New, key), got %x and %x", a, b)
+	}
+}
@@ -2,17 +2,40 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding"
 	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/adler32"
 	"hash/crc32"
 	"hash/crc64"
+	"hash/fnv"
+	"math"
+	"math/big"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // HashFunc is a function signature that calculates hash value of a byte slice.
 type HashFunc func(input []byte) []byte
 
+// Adler32 calculates Adler-32 checksum of a byte slice (4-byte output).
+func Adler32(input []byte) []byte {
+	hf := adler32.New()
+	hf.Write(input)
+	return hf.Sum(nil)
+}
+
 // Crc32 calculates CRC32 hash value of a byte slice.
 func Crc32(input []byte) []byte {
 	hf := crc32.NewIEEE()
@@ -20,13 +43,62 @@ func Crc32(input []byte) []byte {
 	return hf.Sum(nil)
 }
 
-// Crc64 calculates CRC64 hash value of a byte slice.
+// Crc32C calculates CRC32 hash value of a byte slice using the Castagnoli polynomial.
+//
+// CRC32C is hardware-accelerated on CPUs with SSE4.2 (amd64) or the CRC32 extension (arm64).
+func Crc32C(input []byte) []byte {
+	hf := crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	hf.Write(input)
+	return hf.Sum(nil)
+}
+
+// Crc64 calculates CRC64 hash value of a byte slice using the ISO polynomial.
+//
+// See Crc64Ecma for the ECMA-182 polynomial used by xz and many modern formats.
 func Crc64(input []byte) []byte {
 	hf := crc64.New(crc64.MakeTable(crc64.ISO))
 	hf.Write(input)
 	return hf.Sum(nil)
 }
 
+// Crc64Ecma calculates CRC64 hash value of a byte slice using the ECMA-182 polynomial.
+func Crc64Ecma(input []byte) []byte {
+	hf := crc64.New(crc64.MakeTable(crc64.ECMA))
+	hf.Write(input)
+	return hf.Sum(nil)
+}
+
+// NewCrc32Func builds a HashFunc that calculates CRC32 hash value using the specified polynomial.
+//
+// The lookup table is computed once here and shared by all invocations of the returned function.
+func NewCrc32Func(poly uint32) HashFunc {
+	table := crc32.MakeTable(poly)
+	return func(input []byte) []byte {
+		hf := crc32.New(table)
+		hf.Write(input)
+		return hf.Sum(nil)
+	}
+}
+
+// NewCrc64Func builds a HashFunc that calculates CRC64 hash value using the specified polynomial.
+//
+// The lookup table is computed once here and shared by all invocations of the returned function.
+func NewCrc64Func(poly uint64) HashFunc {
+	table := crc64.MakeTable(poly)
+	return func(input []byte) []byte {
+		hf := crc64.New(table)
+		hf.Write(input)
+		return hf.Sum(nil)
+	}
+}
+
+// Fnv1a64 calculates FNV-1a 64-bit hash value of a byte slice (8-byte output).
+func Fnv1a64(input []byte) []byte {
+	hf := fnv.New64a()
+	hf.Write(input)
+	return hf.Sum(nil)
+}
+
 // Md5 calculates MD5 hash value of a byte slice.
 func Md5(input []byte) []byte {
 	hf := md5.New()
@@ -34,29 +106,215 @@ func Md5(input []byte) []byte {
 	return hf.Sum(nil)
 }
 
+// Sha1 calculates SHA-1 hash value of a byte slice (20-byte output).
+//
+// SHA-1 is cryptographically broken; it is provided for compatibility with legacy systems only.
+func Sha1(input []byte) []byte {
+	hf := sha1.New()
+	hf.Write(input)
+	return hf.Sum(nil)
+}
+
+// Sha256 calculates SHA-256 hash value of a byte slice (32-byte output).
+func Sha256(input []byte) []byte {
+	hf := sha256.New()
+	hf.Write(input)
+	return hf.Sum(nil)
+}
+
+// Sha512 calculates SHA-512 hash value of a byte slice (64-byte output).
+func Sha512(input []byte) []byte {
+	hf := sha512.New()
+	hf.Write(input)
+	return hf.Sum(nil)
+}
+
+// Sha512_256 calculates SHA-512/256 hash value of a byte slice (32-byte output).
+//
+// SHA-512/256 is SHA-512 with distinct initial values, truncated to 256 bits: it is usually faster than SHA-256 on
+// 64-bit CPUs without SHA-256 hardware acceleration.
+func Sha512_256(input []byte) []byte {
+	digest := sha512.Sum512_256(input)
+	return digest[:]
+}
+
+var crc64IsoTable = crc64.MakeTable(crc64.ISO)
+
+// Crc32Array is similar to Crc32, but returns the hash value as a fixed-size array.
+func Crc32Array(input []byte) [crc32.Size]byte {
+	var result [crc32.Size]byte
+	binary.BigEndian.PutUint32(result[:], crc32.ChecksumIEEE(input))
+	return result
+}
+
+// Crc64Array is similar to Crc64, but returns the hash value as a fixed-size array.
+func Crc64Array(input []byte) [crc64.Size]byte {
+	var result [crc64.Size]byte
+	binary.BigEndian.PutUint64(result[:], crc64.Checksum(input, crc64IsoTable))
+	return result
+}
+
+// Md5Array is similar to Md5, but returns the hash value as a fixed-size array, e.g. to be used as a map key.
+func Md5Array(input []byte) [md5.Size]byte {
+	return md5.Sum(input)
+}
+
+// nilSentinel is the byte sequence that nil values are hashed from: untyped nil, nil pointers, nil interfaces, nil maps
+// and nil slices all produce hf(nilSentinel). Note that a nil slice is therefore distinct from an empty non-nil slice,
+// which is hashed from the slice marker like any other slice.
+var nilSentinel = []byte("\x00<nil>\x00")
+
+// cycleSentinel is the byte sequence hashed in place of a pointer that refers back to a value currently being walked,
+// which would otherwise lead to infinite recursion.
+var cycleSentinel = []byte("\x00<cycle>\x00")
+
+func isNil(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// Type tags prefixed to the serialized form of primitive values, so that values of different kinds sharing the same
+// binary representation (e.g. int64(1) and uint64(1)) do not produce the same checksum. Aggregate kinds (slices/arrays,
+// maps and structs) seed their checksum from their own tag, so that even an empty aggregate yields a full-length
+// digest.
+//
+// Note: introducing type tags changed the checksum of every primitive value compared to earlier versions.
+const (
+	tagBool     byte = 'b'
+	tagInt      byte = 'i'
+	tagUint     byte = 'u'
+	tagFloat    byte = 'f'
+	tagComplex  byte = 'c'
+	tagString   byte = 's'
+	tagSlice    byte = 'l'
+	tagSet      byte = 'L' // slices and arrays, see WithUnorderedSlices
+	tagMap      byte = 'm'
+	tagStruct   byte = 'o'
+	tagTime     byte = 't'
+	tagDuration byte = 'd'
+	tagBigInt   byte = 'I'
+	tagBigRat   byte = 'R'
+	tagBytes    byte = 'x' // byte slices and arrays, hashed as blobs
+	tagBinary   byte = 'B' // values implementing encoding.BinaryMarshaler
+	tagText     byte = 'T' // values implementing encoding.TextMarshaler
+	tagStringer byte = 'S' // values implementing fmt.Stringer, see WithStringer
+	tagCustom   byte = 'X' // values of types registered via RegisterType
+	tagType     byte = 'Y' // type name of a value, see WithTypeTags
+	tagError    byte = 'E' // error values, by their message
+	tagJSON     byte = 'J' // canonical form of json.RawMessage values, see WithRawJSON
+)
+
 func boolToBytes(v bool) []byte {
 	if v {
-		return []byte{1}
+		return []byte{tagBool, 1}
 	}
-	return []byte{0}
+	return []byte{tagBool, 0}
+}
+
+// taggedUint64 serializes a type tag followed by the 8-byte big-endian representation of v.
+func taggedUint64(tag byte, v uint64) []byte {
+	return appendTaggedUint64(make([]byte, 0, 9), tag, v)
+}
+
+// appendTaggedUint64 appends the serialized form of taggedUint64 to dst. It does not allocate if dst has enough
+// capacity, e.g. when appending length prefixes to a buffer being built.
+func appendTaggedUint64(dst []byte, tag byte, v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return append(append(dst, tag), b[:]...)
+}
+
+// appendUint appends the serialized form of uintToBytes to dst, see appendTaggedUint64.
+func appendUint(dst []byte, v uint64) []byte {
+	return appendTaggedUint64(dst, tagUint, v)
 }
 
 func intToBytes(v int64) []byte {
-	buf := new(bytes.Buffer)
-	binary.Write(buf, binary.BigEndian, v)
-	return buf.Bytes()
+	return taggedUint64(tagInt, uint64(v))
 }
 
 func uintToBytes(v uint64) []byte {
-	buf := new(bytes.Buffer)
-	binary.Write(buf, binary.BigEndian, v)
-	return buf.Bytes()
+	return taggedUint64(tagUint, v)
+}
+
+// canonicalFloatBits returns the IEEE-754 bits of a float: -0.0 is mapped to +0.0, and all NaNs are collapsed into the
+// single bit pattern returned by math.NaN().
+func canonicalFloatBits(v float64) uint64 {
+	if v == 0 {
+		v = 0
+	} else if math.IsNaN(v) {
+		v = math.NaN()
+	}
+	return math.Float64bits(v)
 }
 
 func floatToBytes(v float64) []byte {
-	buf := new(bytes.Buffer)
-	binary.Write(buf, binary.BigEndian, v)
-	return buf.Bytes()
+	return taggedUint64(tagFloat, canonicalFloatBits(v))
+}
+
+func complexToBytes(v complex128) []byte {
+	buf := make([]byte, 17)
+	buf[0] = tagComplex
+	binary.BigEndian.PutUint64(buf[1:], canonicalFloatBits(real(v)))
+	binary.BigEndian.PutUint64(buf[9:], canonicalFloatBits(imag(v)))
+	return buf
+}
+
+// bytesToBytes serializes a byte slice as a blob. The tag keeps blobs apart from other values whose serialized forms
+// happen to have the same bytes, e.g. []byte("sabc") from the string "abc".
+func bytesToBytes(v []byte) []byte {
+	return append([]byte{tagBytes}, v...)
+}
+
+func stringToBytes(v string) []byte {
+	return append([]byte{tagString}, v...)
+}
+
+// timeToBytes serializes the instant represented by a time.Time, regardless of its location and monotonic clock
+// reading.
+func timeToBytes(v time.Time) []byte {
+	buf := make([]byte, 13)
+	buf[0] = tagTime
+	binary.BigEndian.PutUint64(buf[1:], uint64(v.Unix()))
+	binary.BigEndian.PutUint32(buf[9:], uint32(v.Nanosecond()))
+	return buf
+}
+
+// bigIntToBytes serializes a big.Int via its canonical decimal representation.
+func bigIntToBytes(v *big.Int) []byte {
+	return append([]byte{tagBigInt}, v.String()...)
+}
+
+// bigRatToBytes serializes a big.Rat via its canonical (normalized) "a/b" representation.
+func bigRatToBytes(v *big.Rat) []byte {
+	return append([]byte{tagBigRat}, v.String()...)
+}
+
+// maxJSONNumberExponent bounds the exponent of json.Number values, as their exact value is computed.
+const maxJSONNumberExponent = 10000
+
+// jsonNumberToBytes serializes a json.Number by its exact numeric value, like a big.Rat, so that e.g. "1", "1.0" and
+// "1e0" are equivalent.
+func jsonNumberToBytes(v json.Number) ([]byte, error) {
+	s := string(v)
+	if s == "" || (s[0] != '-' && (s[0] < '0' || s[0] > '9')) || !json.Valid([]byte(s)) {
+		return nil, fmt.Errorf("invalid json.Number %q", s)
+	}
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		if exp, err := strconv.Atoi(s[i+1:]); err != nil || exp > maxJSONNumberExponent || exp < -maxJSONNumberExponent {
+			return nil, fmt.Errorf("exponent of json.Number %q out of range", s)
+		}
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid json.Number %q", s)
+	}
+	return bigRatToBytes(r), nil
 }
 
 func ChecksumBool(hf HashFunc, input bool) []byte {
@@ -76,53 +334,988 @@ func ChecksumFloat(hf HashFunc, input float64) []byte {
 }
 
 func ChecksumString(hf HashFunc, input string) []byte {
-	return hf([]byte(input))
+	return hf(stringToBytes(input))
+}
+
+// Checksummer is implemented by types that calculate their own canonical checksum, e.g. to exclude derived or cached
+// fields. Checksum delegates to it instead of walking the value via reflection.
+//
+// Like encoding/json does for Marshaler, a Checksum method with a pointer receiver is also honored for values passed
+// by value (directly, nested, or wrapped in an interface). Such values are not addressable once passed to Checksum,
+// so the method is called on a pointer to a copy of the value: changes it makes to the receiver are not visible to the
+// caller.
+type Checksummer interface {
+	Checksum(hf HashFunc) []byte
+}
+
+var checksummerType = reflect.TypeOf((*Checksummer)(nil)).Elem()
+
+// marshalers lists the interfaces providing the canonical binary form of a value, by order of precedence.
+var marshalers = []struct {
+	tag     byte
+	iface   reflect.Type
+	marshal func(v interface{}) ([]byte, error)
+}{
+	{tagBinary, reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem(), func(v interface{}) ([]byte, error) {
+		return v.(encoding.BinaryMarshaler).MarshalBinary()
+	}},
+	{tagText, reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem(), func(v interface{}) ([]byte, error) {
+		return v.(encoding.TextMarshaler).MarshalText()
+	}},
+}
+
+// methodSet tells how values of a type implement an interface, see implementation.
+type methodSet int
+
+const (
+	noMethod      methodSet = iota // the interface is not implemented, or only via ignored promoted methods
+	ownMethod                      // the type implements the interface
+	pointerMethod                  // only a pointer to the type implements the interface, with pointer receivers
+)
+
+// implementationKey identifies an entry of implementationCache.
+type implementationKey struct {
+	typ, iface reflect.Type
+}
+
+// implementationCache caches the result of implementation: map[implementationKey]methodSet
+var implementationCache sync.Map
+
+var (
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// implementation tells how values of type t implement an interface. Methods with a pointer receiver are honored for
+// values too, so that x and &x are checksummed alike.
+//
+// Methods promoted from an embedded field are ignored: a struct embedding a time.Time or an error is walked field by
+// field, rather than being checksummed as the embedded value alone, and a method is never called through a nil
+// embedded interface or pointer. As a consequence, a struct that embeds an implementation is always walked, even if it
+// declares its own method.
+func implementation(t, iface reflect.Type) methodSet {
+	key := implementationKey{typ: t, iface: iface}
+	if cached, ok := implementationCache.Load(key); ok {
+		return cached.(methodSet)
+	}
+	result := noMethod
+	if t.Implements(iface) {
+		result = ownMethod
+	} else if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(iface) {
+		result = pointerMethod
+	}
+	if result != noMethod && embedsImplementation(t, iface) {
+		result = noMethod
+	}
+	implementationCache.Store(key, result)
+	return result
 }
 
+// implementer returns v if it implements an interface, or a pointer to a copy of v if only pointers to its type do,
+// see implementation. It returns nil if the interface is not implemented.
+func implementer(v interface{}, rv reflect.Value, iface reflect.Type) interface{} {
+	switch implementation(rv.Type(), iface) {
+	case ownMethod:
+		return v
+	case pointerMethod:
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		return ptr.Interface()
+	}
+	return nil
+}
+
+// embedsImplementation reports whether a struct type (or pointer to struct type) has an embedded field implementing
+// an interface, with value or pointer receivers.
+func embedsImplementation(t, iface reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i, n := 0, t.NumField(); i < n; i++ {
+		if f := t.Field(i); f.Anonymous && (f.Type.Implements(iface) || reflect.PtrTo(f.Type).Implements(iface)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChecksumError describes a value that could not be checksummed, and where it is located in the input.
+type ChecksumError struct {
+	Path string // location of the offending value, e.g. "Items[2].Handler" or `Env["HOME"]`; empty for the top-level value
+	Err  error
+}
+
+func (e *ChecksumError) Error() string {
+	if e.Path == "" {
+		return "checksum: " + e.Err.Error()
+	}
+	return "checksum: " + strings.TrimPrefix(e.Path, ".") + ": " + e.Err.Error()
+}
+
+func (e *ChecksumError) Unwrap() error {
+	return e.Err
+}
+
+// ErrUnsupportedKind is wrapped by the ChecksumError returned by ChecksumE for values of kinds that can not be
+// checksummed, such as channels, functions, uintptrs and unsafe pointers.
+var ErrUnsupportedKind = errors.New("unsupported kind")
+
+// ErrDuplicateFieldName is wrapped by the ChecksumError returned by ChecksumE for structs having two fields with the
+// same checksum name, e.g. because of a `checksum:"name"` tag.
+var ErrDuplicateFieldName = errors.New("duplicate field name")
+
+// mapKeySegment returns the path segment of a map entry, for ChecksumError.Path and tracing. Keys are formatted in Go
+// syntax, so that e.g. the int key 1 and the string key "1" have distinct paths; keys of interface-typed maps are
+// prefixed with their concrete type, e.g. [int64(1)].
+func mapKeySegment(rv, key reflect.Value) string {
+	if rv.Type().Key().Kind() == reflect.Interface {
+		return fmt.Sprintf("[%T(%#v)]", key.Interface(), key.Interface())
+	}
+	return fmt.Sprintf("[%#v]", key.Interface())
+}
+
+// withPath prefixes the path of a ChecksumError with the location of the value being walked.
+func withPath(err error, segment string) error {
+	if ce, ok := err.(*ChecksumError); ok {
+		ce.Path = segment + ce.Path
+	}
+	return err
+}
+
+// orderedHeader starts the buffer combining the checksums of n ordered values. The checksums are appended to it via
+// appendOrdered, and the whole buffer is hashed once at the end.
+func orderedHeader(n int) []byte {
+	return appendUint([]byte{tagSlice}, uint64(n))
+}
+
+// appendOrdered appends the checksum of the next value to buf, length-prefixing it so that element boundaries are
+// always unambiguous, e.g. []string{"ab", "c"} never collides with []string{"a", "bc"}.
+func appendOrdered(buf, temp []byte) []byte {
+	buf = appendUint(buf, uint64(len(temp)))
+	return append(buf, temp...)
+}
+
+var syncMapType = reflect.TypeOf(sync.Map{})
+
+// syncMapContainersCache caches the result of containsSyncMap for struct and array types: map[reflect.Type]bool
+var syncMapContainersCache sync.Map
+
+// containsSyncMap reports whether values of a type hold a sync.Map by value, possibly nested in struct fields (exported
+// or not) or array elements. Copying such a value races with concurrent writers to the sync.Map.
+func containsSyncMap(t reflect.Type) bool {
+	if k := t.Kind(); k != reflect.Struct && k != reflect.Array {
+		return false
+	}
+	if cached, ok := syncMapContainersCache.Load(t); ok {
+		return cached.(bool)
+	}
+	result := t == syncMapType
+	if t.Kind() == reflect.Array {
+		result = containsSyncMap(t.Elem())
+	}
+	for i := 0; !result && t.Kind() == reflect.Struct && i < t.NumField(); i++ {
+		result = containsSyncMap(t.Field(i).Type)
+	}
+	syncMapContainersCache.Store(t, result)
+	return result
+}
+
+// interfaceOf returns the value held by rv, like rv.Interface(), but as a pointer if rv is addressable and holds a
+// sync.Map by value. Pointers have the same checksum as the values they point to, and this way, a sync.Map reached
+// through a pointer (e.g. as the field of a struct passed by pointer) is walked in place, without being copied.
+func interfaceOf(rv reflect.Value) interface{} {
+	if rv.CanAddr() && containsSyncMap(rv.Type()) {
+		return rv.Addr().Interface()
+	}
+	return rv.Interface()
+}
+
+// syncMapEntries copies the entries of a sync.Map into a built-in map, so that both are checksummed the same way,
+// regardless of iteration order.
+func syncMapEntries(m *sync.Map) map[interface{}]interface{} {
+	entries := map[interface{}]interface{}{}
+	m.Range(func(k, v interface{}) bool {
+		entries[k] = v
+		return true
+	})
+	return entries
+}
+
+// visitKey identifies a pointer, slice or map being walked; the type is part of the key since a pointer to a struct
+// and a pointer to its first field share the same address, and so is the length of slices since a slice and its
+// sub-slices may share the same address.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// visit marks a reference as being walked, until the returned function is called. It returns false if the reference
+// is already on the current path from the root value, i.e. if it is cyclic.
+func (w *walker) visit(rv reflect.Value) (bool, func()) {
+	key := visitKey{ptr: rv.Pointer(), typ: rv.Type()}
+	if rv.Kind() == reflect.Slice {
+		key.len = rv.Len()
+	}
+	if w.visiting[key] {
+		return false, nil
+	}
+	if w.visiting == nil {
+		// allocated on first use, as most primitive values never need it
+		w.visiting = make(map[visitKey]bool)
+	}
+	w.visiting[key] = true
+	return true, func() { delete(w.visiting, key) }
+}
+
+// ctxCheckInterval is the number of values walked between two checks of the context.
+const ctxCheckInterval = 1024
+
+// walker carries the hash function, options and state of a checksum calculation through the recursive walk.
+type walker struct {
+	ctx      context.Context
+	hf       HashFunc
+	cfg      config
+	visiting map[visitKey]bool // pointers, slices and maps on the current path from the root value
+	steps    int               // number of values walked so far
+	parallel bool              // if true, elements of the next slice/array walked are checksummed concurrently
+
+	tracing bool         // if true, every hash calculated is recorded to trace, see ChecksumTrace
+	path    []string     // location of the value being walked, only maintained when tracing
+	trace   []TraceEntry // hashes calculated so far, only maintained when tracing
+}
+
+// encodeUnordered serializes a set of checksums (or encoded slice elements) regardless of their order, into a buffer to
+// be hashed once: they are sorted, then concatenated after the tag and their count. Unlike XOR-combining them, equal
+// checksums do not cancel each other out.
+func encodeUnordered(tag byte, temps [][]byte) []byte {
+	sort.Slice(temps, func(i, j int) bool {
+		return bytes.Compare(temps[i], temps[j]) < 0
+	})
+	buf := appendUint([]byte{tag}, uint64(len(temps)))
+	for _, temp := range temps {
+		buf = append(appendUint(buf, uint64(len(temp))), temp...)
+	}
+	return buf
+}
+
+// checksumTuple combines the checksums of a fixed sequence of values, like Combine. Since each value is checksummed on
+// its own and its checksum is length-prefixed, the values' serialized forms are never concatenated: e.g. the map entry
+// {"ab": "c"} never collides with {"a": "bc"}.
+func (w *walker) checksumTuple(values ...interface{}) ([]byte, error) {
+	buf := orderedHeader(len(values))
+	for _, v := range values {
+		temp, err := w.checksum(v)
+		if err != nil {
+			return nil, err
+		}
+		buf = appendOrdered(buf, temp)
+	}
+	return w.hf(buf), nil
+}
+
+// structField describes how a struct field participates in the checksum.
+type structField struct {
+	index  []int  // index sequence of the field, as accepted by reflect.Value.FieldByIndex
+	goName string // Go name of the field
+	name   string // name mixed into the checksum: value of the `checksum:"name"` tag if present, the Go field name otherwise
+	depth  int    // embedding depth of the field, 0 for fields declared directly on the struct
+}
+
+// fieldOptions are the options affecting which fields of a struct participate in the checksum, and under which name.
+type fieldOptions struct {
+	flatten  bool // see WithFlattenEmbedded
+	foldCase bool // see WithCaseInsensitiveFields
+	strict   bool // see WithStrictFields
+
+	promoteUnexported bool // see ChecksumExported
+}
+
+// structFields returns the fields of a struct type that participate in the checksum, in declaration order.
+//
+// Unexported fields and fields tagged `checksum:"-"` are excluded entirely, name included. If opts.flatten is true,
+// fields of embedded structs are promoted following Go's rules: a shallower field hides deeper ones with the same
+// name, and promoted fields that would be ambiguous at the same depth are excluded. If opts.promoteUnexported is true,
+// the same applies to fields of unexported embedded structs only.
+func structFields(t reflect.Type, opts fieldOptions) ([]structField, error) {
+	candidates := collectStructFields(t, opts, nil, 0, map[reflect.Type]bool{})
+	minDepth := make(map[string]int, len(candidates))
+	count := make(map[string]int, len(candidates))
+	for _, f := range candidates {
+		if d, ok := minDepth[f.name]; !ok || f.depth < d {
+			minDepth[f.name] = f.depth
+			count[f.name] = 0
+		}
+		if f.depth == minDepth[f.name] {
+			count[f.name]++
+		}
+	}
+	fields := make([]structField, 0, len(candidates))
+	for _, f := range candidates {
+		if f.depth != minDepth[f.name] {
+			continue
+		}
+		if count[f.name] > 1 {
+			if f.depth == 0 {
+				return nil, &ChecksumError{Err: fmt.Errorf("%w %q", ErrDuplicateFieldName, f.name)}
+			}
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// structFieldsKey identifies an entry of structFieldsCache.
+type structFieldsKey struct {
+	typ  reflect.Type
+	opts fieldOptions
+}
+
+// structFieldsCache caches the result of structFields: map[structFieldsKey][]structField
+var structFieldsCache sync.Map
+
+// cachedStructFields is similar to structFields, but computes the fields of each struct type only once.
+func cachedStructFields(t reflect.Type, opts fieldOptions) ([]structField, error) {
+	key := structFieldsKey{typ: t, opts: opts}
+	if cached, ok := structFieldsCache.Load(key); ok {
+		return cached.([]structField), nil
+	}
+	fields, err := structFields(t, opts)
+	if err != nil {
+		// errors are not cached: the returned error is mutated while unwinding the walk
+		return nil, err
+	}
+	structFieldsCache.Store(key, fields)
+	return fields, nil
+}
+
+// canonicalStructTypes lists the struct types that encode checksums by a canonical form rather than field by field.
+var canonicalStructTypes = map[reflect.Type]bool{
+	reflect.TypeOf(time.Time{}): true,
+	reflect.TypeOf(big.Int{}):   true,
+	reflect.TypeOf(big.Rat{}):   true,
+	syncMapType:                 true,
+}
+
+// hasCanonicalForm reports whether values of a struct type are checksummed as a whole rather than walked field by
+// field: special-cased and registered types, and implementations of Checksummer, error or a marshaler. Their fields
+// are usually unexported, so flattening them would drop their content from the checksum.
+func hasCanonicalForm(t reflect.Type) bool {
+	if _, ok := typeSerializer(t); ok || canonicalStructTypes[t] {
+		return true
+	}
+	if implementation(t, checksummerType) != noMethod || implementation(t, errorType) != noMethod {
+		return true
+	}
+	for _, m := range marshalers {
+		if implementation(t, m.iface) != noMethod {
+			return true
+		}
+	}
+	return false
+}
+
+// collectStructFields lists the candidate fields of a struct type, descending into embedded structs if opts.flatten
+// (or, for unexported ones, opts.promoteUnexported) is true. Exported embedded structs with a canonical form are kept
+// as single fields, see hasCanonicalForm; unexported ones can not be read as a whole, so they are flattened anyway.
+func collectStructFields(t reflect.Type, opts fieldOptions, index []int, depth int, visiting map[reflect.Type]bool) []structField {
+	visiting[t] = true
+	defer delete(visiting, t)
+	var fields []structField
+	for i, n := 0, t.NumField(); i < n; i++ {
+		sf := t.Field(i)
+		name := sf.Tag.Get("checksum")
+		if name == "-" {
+			continue
+		}
+		fieldIndex := append(append(make([]int, 0, len(index)+1), index...), i)
+		if sf.Anonymous && name == "" && (opts.flatten || (opts.promoteUnexported && sf.PkgPath != "")) {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !visiting[ft] && (sf.PkgPath != "" || !hasCanonicalForm(ft)) {
+				fields = append(fields, collectStructFields(ft, opts, fieldIndex, depth+1, visiting)...)
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			// unexported field: its value can not be read via reflection
+			continue
+		}
+		if k := sf.Type.Kind(); (k == reflect.Func || k == reflect.Chan) && !opts.strict {
+			// callbacks and channels carry behavior rather than data
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if opts.foldCase {
+			name = strings.ToLower(name)
+		}
+		fields = append(fields, structField{index: fieldIndex, goName: sf.Name, name: name, depth: depth})
+	}
+	return fields
+}
+
+// Checksum calculates checksum of a value using the specified hash function.
+//
+// Numbers are checksummed by value regardless of their width, so that checksums tolerate schema changes: int8(1),
+// int32(1) and int64(1) have the same checksum, and so do uint8(1) and uint64(1), or float32(0.5) and float64(0.5).
+// Signed integers, unsigned integers and floats remain distinct though: int(1), uint(1) and float64(1) have different
+// checksums. Use WithTypeTags to also distinguish widths. A time.Duration is distinct from any integer too, e.g. 5s
+// differs from int64(5000000000). A json.Number is checksummed by its exact value, like a big.Rat: e.g.
+// json.Number("1.0") and json.Number("1") have the same checksum.
+//
+// Pointers are checksummed by the content they point to, never by address: for any value x, including arrays, slices
+// and maps, Checksum(hf, &x) equals Checksum(hf, x). This holds at every level of nesting, whatever the options: e.g. a
+// struct field of type *int pointing to 1 contributes the same as a field of type int set to 1, and a []*T the same as
+// a []T. Methods with a pointer receiver (Checksummer, error, fmt.Stringer and marshalers) are honored for values too,
+// e.g. a big.Float is checksummed like a *big.Float. The only exception is cyclic values, as the cycle is detected one
+// level deeper from &x than from x. Nil pointers of any type have the same checksum as untyped nil, which differs from
+// a pointer to a zero value.
+//
+// Values implementing encoding.BinaryMarshaler (or else encoding.TextMarshaler), such as net.IP or big.Float, are
+// checksummed by their marshaled form rather than walked, see implementation.
+//
+// Error values are checksummed by their message: errors with the same Error() string have the same checksum, whatever
+// their concrete types. A struct embedding an error is walked like any other struct, see implementation.
+//
+// Byte slices and arrays are hashed as blobs: their checksum is hf of the bytes prefixed with a type tag, rather than
+// hf(b), so that a blob never has the same checksum as another value with the same serialized form. Use hf directly
+// to hash raw bytes.
+//
+// A sync.Map has the same checksum as a built-in map with the same entries: like for built-in maps, the checksum does
+// not depend on iteration order. A sync.Map reached through a pointer, e.g. the field of a struct passed by pointer, is
+// read in place and may be written concurrently; one passed by value is copied, which races with concurrent writers.
+//
+// Checksum returns nil if the value (or any value nested inside it) can not be checksummed; use ChecksumE to find
+// out why.
 func Checksum(hf HashFunc, v interface{}) []byte {
+	result, _ := ChecksumE(hf, v)
+	return result
+}
+
+// ChecksumT is the type-parameterized version of Checksum. A few common types skip the reflection walk, unless they are
+// registered via RegisterType.
+func ChecksumT[T any](hf HashFunc, v T) []byte {
+	if _, ok := typeSerializer(reflect.TypeOf(v)); ok {
+		return Checksum(hf, v)
+	}
+	switch t := any(v).(type) {
+	case string:
+		return ChecksumString(hf, t)
+	case int64:
+		return ChecksumInt(hf, t)
+	case []byte:
+		if t != nil {
+			return hf(bytesToBytes(t))
+		}
+	}
+	return Checksum(hf, v)
+}
+
+// ChecksumEach calculates the checksum of each value independently, e.g. to fingerprint many records at once: result i
+// equals Checksum(hf, vs[i]), rather than being combined into a single checksum like for Checksum(hf, vs). The state of
+// the calculation is reused across values.
+func ChecksumEach(hf HashFunc, vs []interface{}) [][]byte {
+	w := newWalker(context.Background(), hf, nil)
+	results := make([][]byte, len(vs))
+	for i, v := range vs {
+		results[i], _ = w.checksum(v)
+	}
+	return results
+}
+
+// Combine combines ordered checksums (e.g. of parts of a document calculated independently) into one, without the
+// need to rehash the source. Digests are length-prefixed so that their boundaries are unambiguous.
+//
+// Note that Combine(hf, Checksum(hf, a), Checksum(hf, b)) differs from Checksum(hf, []interface{}{a, b}): slice
+// elements are not checksummed individually, their serialized forms are hashed together at once.
+func Combine(hf HashFunc, digests ...[]byte) []byte {
+	buf := orderedHeader(len(digests))
+	for _, digest := range digests {
+		buf = appendOrdered(buf, digest)
+	}
+	return hf(buf)
+}
+
+// ChecksumE is similar to Checksum, but returns a *ChecksumError if the value (or any value nested inside it) can
+// not be checksummed.
+func ChecksumE(hf HashFunc, v interface{}) ([]byte, error) {
+	return ChecksumWith(hf, v)
+}
+
+// ChecksumExported is similar to ChecksumE, as a dedicated entry point for fingerprinting exported state (e.g. API
+// responses): at every level of nesting, only exported struct fields are checksummed, and unexported fields, including
+// their names, do not affect the result at all.
+//
+// Like encoding/json, and unlike ChecksumE, it promotes the exported fields of unexported embedded structs: e.g. the
+// field X of Outer{inner{X: 1}, Y: 2} is checksummed as if declared on Outer, rather than being dropped along with the
+// unexported field inner. Embedded structs of exported types are still checksummed as a single field, see
+// WithFlattenEmbedded.
+func ChecksumExported(hf HashFunc, v interface{}) ([]byte, error) {
+	return ChecksumWith(hf, v, func(cfg *config) {
+		cfg.fields.promoteUnexported = true
+	})
+}
+
+// ChecksumWith is similar to ChecksumE, but customizes the calculation with options. Options are applied in order, and
+// are independent of each other unless documented otherwise:
+//   - WithFlattenEmbedded, WithCaseInsensitiveFields, WithStrictFields and WithOrderedStruct control how structs are
+//     checksummed
+//   - WithSortedMap controls how maps are checksummed
+//   - WithUnorderedSlices controls how slices and arrays are checksummed
+//   - WithRawJSON controls how json.RawMessage values are checksummed
+//   - WithTypeTags mixes the concrete type of each value into its checksum
+//   - WithStringNormalization and WithStringer control how strings and fmt.Stringer values are checksummed
+//
+// Checksum and ChecksumE are equivalent to ChecksumWith without options.
+func ChecksumWith(hf HashFunc, v interface{}, opts ...Option) ([]byte, error) {
+	return newWalker(context.Background(), hf, opts).checksum(v)
+}
+
+// ChecksumContext is similar to ChecksumWith, but aborts the calculation and returns the context's error as soon as
+// ctx is done. The context is checked before the walk starts, then every ctxCheckInterval values walked.
+func ChecksumContext(ctx context.Context, hf HashFunc, v interface{}, opts ...Option) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return newWalker(ctx, hf, opts).checksum(v)
+}
+
+func newWalker(ctx context.Context, hf HashFunc, opts []Option) *walker {
+	w := &walker{ctx: ctx, hf: hf}
+	for _, opt := range opts {
+		opt(&w.cfg)
+	}
+	return w
+}
+
+func (w *walker) checksum(v interface{}) ([]byte, error) {
+	digest, _, err := w.checksumRaw(v)
+	return digest, err
+}
+
+// checksumRaw is similar to checksum, but also returns the canonical bytes the checksum was calculated from (or the
+// checksum itself, if it was calculated from nested values).
+func (w *walker) checksumRaw(v interface{}) (digest, raw []byte, err error) {
+	data, isDigest, err := w.encodeTyped(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isDigest {
+		w.record(nil, data)
+		return data, data, nil
+	}
+	digest = w.hf(data)
+	w.record(data, digest)
+	return digest, data, nil
+}
+
+var typeNameCache sync.Map
+
+// typeName returns the name of a type as mixed into checksums by WithTypeTags: the name of its underlying type with all
+// unnamed pointer levels removed, e.g. "map[string]int" for *map[string]*int or "struct { P int }" for
+// struct{ P *int }, so that pointers still have the same checksum as the values they point to.
+func typeName(t reflect.Type) string {
+	if name, ok := typeNameCache.Load(t); ok {
+		return name.(string)
+	}
+	var name string
+	switch {
+	case t.Kind() == reflect.Ptr && t.Name() == "":
+		name = typeName(t.Elem())
+	case t.Name() != "":
+		name = t.String()
+	case t.Kind() == reflect.Slice:
+		name = "[]" + typeName(t.Elem())
+	case t.Kind() == reflect.Array:
+		name = fmt.Sprintf("[%d]%s", t.Len(), typeName(t.Elem()))
+	case t.Kind() == reflect.Map:
+		name = "map[" + typeName(t.Key()) + "]" + typeName(t.Elem())
+	case t.Kind() == reflect.Struct:
+		// formatted like reflect does, e.g. "struct { A int; B string }", but with field types named by typeName
+		fields := make([]string, t.NumField())
+		for i := range fields {
+			f := t.Field(i)
+			fields[i] = typeName(f.Type)
+			if !f.Anonymous {
+				fields[i] = f.Name + " " + fields[i]
+			}
+			if f.Tag != "" {
+				fields[i] += " " + strconv.Quote(string(f.Tag))
+			}
+		}
+		if len(fields) == 0 {
+			name = "struct {}"
+		} else {
+			name = "struct { " + strings.Join(fields, "; ") + " }"
+		}
+	default:
+		name = t.String()
+	}
+	typeNameCache.Store(t, name)
+	return name
+}
+
+// encodeTyped is similar to encode, but also mixes the value's type name into the result if WithTypeTags is enabled.
+// Nil values, including pointers to nil maps or slices, are not named.
+func (w *walker) encodeTyped(v interface{}) ([]byte, bool, error) {
+	data, isDigest, err := w.encode(v)
+	if err != nil || !w.cfg.typeTags || (!isDigest && bytes.Equal(data, nilSentinel)) {
+		return data, isDigest, err
+	}
+	name := stringToBytes(typeName(reflect.TypeOf(v)))
+	buf := append(appendUint([]byte{tagType}, uint64(len(name))), name...)
+	return appendEncoded(buf, data, isDigest), false, nil
+}
+
+// appendEncoded appends the result of encode (or encodeTyped) to buf: a marker telling serialized forms and checksums
+// apart, then the data prefixed with its length.
+func appendEncoded(buf, data []byte, isDigest bool) []byte {
+	marker := byte('r')
+	if isDigest {
+		marker = 'd'
+	}
+	buf = appendUint(append(buf, marker), uint64(len(data)))
+	return append(buf, data...)
+}
+
+// encodeElement appends the encoded form of element i of a slice or array to buf, see appendEncoded. When tracing, the
+// element's checksum is recorded too, as if it had been checksummed on its own.
+func (w *walker) encodeElement(buf []byte, rv reflect.Value, i int) ([]byte, error) {
+	if w.tracing {
+		w.enter(fmt.Sprintf("[%d]", i))
+	}
+	data, isDigest, err := w.encodeTyped(interfaceOf(rv.Index(i)))
+	if err == nil && w.tracing {
+		if isDigest {
+			w.record(nil, data)
+		} else {
+			w.record(data, w.hf(data))
+		}
+	}
+	w.leave()
+	if err != nil {
+		return nil, withPath(err, fmt.Sprintf("[%d]", i))
+	}
+	return appendEncoded(buf, data, isDigest), nil
+}
+
+// encode returns either the serialized form of a value, to be hashed by the caller (isDigest = false), or its
+// checksum if it had to be calculated from nested values (isDigest = true).
+func (w *walker) encode(v interface{}) (data []byte, isDigest bool, err error) {
+	if w.steps++; w.steps%ctxCheckInterval == 0 {
+		if err := w.ctx.Err(); err != nil {
+			return nil, false, err
+		}
+	}
+	hf := w.hf
 	rv := reflect.ValueOf(v)
+	if isNil(rv) {
+		return nilSentinel, false, nil
+	}
+	if fn, ok := typeSerializer(rv.Type()); ok {
+		return append([]byte{tagCustom}, fn(v)...), false, nil
+	}
+	if c, ok := implementer(v, rv, checksummerType).(Checksummer); ok {
+		return c.Checksum(hf), true, nil
+	}
+	if e, ok := implementer(v, rv, errorType).(error); ok {
+		// errors are often backed by unexported fields, their message is what identifies them
+		return append([]byte{tagError}, e.Error()...), false, nil
+	}
+	switch t := v.(type) {
+	case time.Time:
+		return timeToBytes(t), false, nil
+	case *time.Time:
+		return timeToBytes(*t), false, nil
+	case time.Duration:
+		return taggedUint64(tagDuration, uint64(t)), false, nil
+	case big.Int:
+		return bigIntToBytes(&t), false, nil
+	case *big.Int:
+		return bigIntToBytes(t), false, nil
+	case big.Rat:
+		return bigRatToBytes(&t), false, nil
+	case *big.Rat:
+		return bigRatToBytes(t), false, nil
+	case *sync.Map:
+		// a sync.Map may contain itself, like any pointer
+		ok, leave := w.visit(rv)
+		if !ok {
+			return cycleSentinel, false, nil
+		}
+		defer leave()
+		// sync.Map hides its entries from reflection: they are collected via Range and hashed as a built-in map
+		return w.encode(syncMapEntries(t))
+	case json.RawMessage:
+		if w.cfg.rawJSON {
+			data, err := canonicalJSON(t)
+			if err != nil {
+				return nil, false, &ChecksumError{Err: err}
+			}
+			return append([]byte{tagJSON}, data...), false, nil
+		}
+		// otherwise hashed as an opaque blob by the byte slice fast path
+	case json.Number:
+		data, err := jsonNumberToBytes(t)
+		if err != nil {
+			return nil, false, &ChecksumError{Err: err}
+		}
+		return data, false, nil
+	}
+	if w.cfg.stringer {
+		if s, ok := implementer(v, rv, stringerType).(fmt.Stringer); ok {
+			// after the canonical forms above, e.g. String() of a time.Time depends on its location
+			return append([]byte{tagStringer}, s.String()...), false, nil
+		}
+	}
+	for _, m := range marshalers {
+		mv := implementer(v, rv, m.iface)
+		if mv == nil {
+			continue
+		}
+		data, err := m.marshal(mv)
+		if err != nil {
+			return nil, false, &ChecksumError{Err: err}
+		}
+		return append([]byte{m.tag}, data...), false, nil
+	}
+	if rv.Type() == syncMapType {
+		// copied through reflection, as sync.Map values must not be copied explicitly
+		ptr := reflect.New(syncMapType)
+		ptr.Elem().Set(rv)
+		return w.encode(syncMapEntries(ptr.Interface().(*sync.Map)))
+	}
+	return w.encodeKind(rv)
+}
+
+// encodeKind is the part of encode serializing a value by its kind. Unlike encode, it also accepts addressable values,
+// which it walks in place, see interfaceOf.
+func (w *walker) encodeKind(rv reflect.Value) (data []byte, isDigest bool, err error) {
 	switch rv.Kind() {
 	case reflect.Bool:
-		return hf(boolToBytes(rv.Bool()))
+		return boolToBytes(rv.Bool()), false, nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return hf(intToBytes(rv.Int()))
+		return intToBytes(rv.Int()), false, nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return hf(uintToBytes(rv.Uint()))
+		return uintToBytes(rv.Uint()), false, nil
 	case reflect.Float32, reflect.Float64:
-		return hf(floatToBytes(rv.Float()))
+		return floatToBytes(rv.Float()), false, nil
+	case reflect.Complex64, reflect.Complex128:
+		return complexToBytes(rv.Complex()), false, nil
+	case reflect.Uintptr:
+		// a uintptr is a memory address rather than data, so it is rejected instead of being hashed like a uint
+		return nil, false, &ChecksumError{Err: fmt.Errorf("%w %s (memory addresses are not checksummed)", ErrUnsupportedKind, rv.Kind())}
 	case reflect.String:
-		return hf([]byte(rv.String()))
+		if w.cfg.normalizeStrings {
+			return stringToBytes(w.cfg.stringForm.String(rv.String())), false, nil
+		}
+		return stringToBytes(rv.String()), false, nil
+	case reflect.Ptr:
+		ok, leave := w.visit(rv)
+		if !ok {
+			return cycleSentinel, false, nil
+		}
+		defer leave()
+		if elem := rv.Elem(); containsSyncMap(elem.Type()) {
+			// walked in place rather than copied, see interfaceOf; methods were already checked on the pointer
+			if _, ok := typeSerializer(elem.Type()); !ok {
+				return w.encodeKind(elem)
+			}
+		}
+		// hash by content: a pointer to an array, slice or map is equivalent to the pointed-to value
+		return w.encode(rv.Elem().Interface())
+	case reflect.Interface:
+		// reflect.Value.Interface always boxes the concrete value, so interface wrappers never nest here; chains of
+		// pointers to interfaces (e.g. *interface{} holding another *interface{}) are unwrapped by the reflect.Ptr case,
+		// one level per call, down to the concrete value
+		return w.encode(rv.Elem().Interface())
 	case reflect.Array, reflect.Slice:
-		buf := make([]byte, 0)
-		for i, n := 0, rv.Len(); i < n; i++ {
-			buf = hf(append(buf, Checksum(hf, rv.Index(i).Interface())...))
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			// fast path: byte slices and arrays (including named types such as "type Blob []byte") are hashed as blobs
+			if rv.Kind() == reflect.Slice {
+				return bytesToBytes(rv.Bytes()), false, nil
+			}
+			buf := make([]byte, 1+rv.Len())
+			buf[0] = tagBytes
+			reflect.Copy(reflect.ValueOf(buf[1:]), rv)
+			return buf, false, nil
+		}
+		n := rv.Len()
+		if rv.Kind() == reflect.Slice && n > 0 {
+			// a slice may contain itself, e.g. via an []interface{} element
+			ok, leave := w.visit(rv)
+			if !ok {
+				return cycleSentinel, false, nil
+			}
+			defer leave()
+		}
+		// elements are not checksummed individually: their encoded forms are hashed at once by the caller
+		var entries [][]byte
+		if w.parallel {
+			w.parallel = false
+			if n >= parallelMinLen {
+				var err error
+				if entries, err = w.parallelEncodings(rv); err != nil {
+					return nil, false, err
+				}
+			}
+		}
+		if entries == nil && w.cfg.unorderedSlices {
+			entries = make([][]byte, n)
+			for i := 0; i < n; i++ {
+				var err error
+				if entries[i], err = w.encodeElement(nil, rv, i); err != nil {
+					return nil, false, err
+				}
+			}
 		}
-		return buf
+		if w.cfg.unorderedSlices {
+			return encodeUnordered(tagSet, entries), false, nil
+		}
+		buf := orderedHeader(n)
+		for i := 0; i < n; i++ {
+			if entries != nil {
+				buf = append(buf, entries[i]...)
+				continue
+			}
+			var err error
+			if buf, err = w.encodeElement(buf, rv, i); err != nil {
+				return nil, false, err
+			}
+		}
+		return buf, false, nil
 	case reflect.Map:
-		buf := hf([]byte{})
+		if rv.Len() > 0 {
+			// a map may contain itself, e.g. via a map[string]interface{} value
+			ok, leave := w.visit(rv)
+			if !ok {
+				return cycleSentinel, false, nil
+			}
+			defer leave()
+		}
+		if w.cfg.sortedMap {
+			return w.encodeSortedMap(rv)
+		}
+		temps := make([][]byte, 0, rv.Len())
 		for iter := rv.MapRange(); iter.Next(); {
-			temp := Checksum(hf, []interface{}{iter.Key().Interface(), iter.Value().Interface()})
-			for i, n := 0, len(buf); i < n; i++ {
-				buf[i] ^= temp[i]
+			if w.tracing {
+				w.enter(mapKeySegment(rv, iter.Key()))
 			}
-			// fmt.Printf("{key: %#v / value: %#v} %x - %x\n", iter.Key().Interface(), iter.Value().Interface(), temp, buf)
+			temp, err := w.checksumTuple(iter.Key().Interface(), iter.Value().Interface())
+			w.leave()
+			if err != nil {
+				return nil, false, withPath(err, mapKeySegment(rv, iter.Key()))
+			}
+			temps = append(temps, temp)
 		}
-		return buf
+		return encodeUnordered(tagMap, temps), false, nil
 	case reflect.Struct:
-		buf := hf([]byte{})
-		for i, n := 0, rv.NumField(); i < n; i++ {
-			fieldName := rv.Type().Field(i).Name
-			fieldValue := rv.Field(i)
-			temp := Checksum(hf, []interface{}{fieldName, fieldValue.Interface()})
-			for i, n := 0, len(buf); i < n; i++ {
-				buf[i] ^= temp[i]
+		fields, err := cachedStructFields(rv.Type(), w.cfg.fields)
+		if err != nil {
+			return nil, false, err
+		}
+		if w.cfg.orderedStruct {
+			return w.encodeOrderedStruct(rv, fields)
+		}
+		temps := make([][]byte, 0, len(fields))
+		for _, field := range fields {
+			if w.tracing {
+				w.enter("." + field.goName)
+			}
+			temp, err := w.checksumTuple(field.name, fieldValue(rv, field))
+			w.leave()
+			if err != nil {
+				return nil, false, withPath(err, "."+field.goName)
 			}
+			temps = append(temps, temp)
+		}
+		return encodeUnordered(tagStruct, temps), false, nil
+	}
+	return nil, false, &ChecksumError{Err: fmt.Errorf("%w %s", ErrUnsupportedKind, rv.Kind())}
+}
+
+// encodeSortedMap serializes a map's entries sorted by the checksum of their keys into a single buffer hashed once by
+// the caller: for each entry the checksum of its key then the checksum of its value, each prefixed with its length.
+//
+// Entries whose key checksums collide are ordered by the canonical bytes of their keys, then by the checksum of their
+// values, so that the order is fully deterministic.
+func (w *walker) encodeSortedMap(rv reflect.Value) ([]byte, bool, error) {
+	type entry struct{ key, rawKey, value []byte }
+	entries := make([]entry, 0, rv.Len())
+	for iter := rv.MapRange(); iter.Next(); {
+		if w.tracing {
+			w.enter(mapKeySegment(rv, iter.Key()))
+		}
+		key, rawKey, err := w.checksumRaw(iter.Key().Interface())
+		if err != nil {
+			w.leave()
+			return nil, false, withPath(err, mapKeySegment(rv, iter.Key()))
+		}
+		value, err := w.checksum(iter.Value().Interface())
+		w.leave()
+		if err != nil {
+			return nil, false, withPath(err, mapKeySegment(rv, iter.Key()))
 		}
-		return buf
+		entries = append(entries, entry{key: key, rawKey: rawKey, value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if c := bytes.Compare(entries[i].key, entries[j].key); c != 0 {
+			return c < 0
+		}
+		if c := bytes.Compare(entries[i].rawKey, entries[j].rawKey); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(entries[i].value, entries[j].value) < 0
+	})
+	buf := appendUint([]byte{tagMap}, uint64(len(entries)))
+	for _, e := range entries {
+		buf = append(appendUint(buf, uint64(len(e.key))), e.key...)
+		buf = append(appendUint(buf, uint64(len(e.value))), e.value...)
+	}
+	return buf, false, nil
+}
+
+// fieldValue returns the value of a struct field; fields promoted through a nil embedded pointer are returned as nil.
+//
+// Interface-typed fields are returned unwrapped, so a field holding a nil interface is returned as untyped nil: like
+// any nil value it is hashed from nilSentinel, combined with the field name, and thus differs from a field holding a
+// non-nil value.
+func fieldValue(rv reflect.Value, field structField) interface{} {
+	if fv, err := rv.FieldByIndexErr(field.index); err == nil {
+		return interfaceOf(fv)
 	}
 	return nil
 }
 
+// encodeOrderedStruct serializes a struct's fields sequentially, in declaration order, into a single buffer hashed
+// once by the caller: for each field its name, then either its serialized form or (for aggregates) its checksum, each
+// prefixed with a marker and its length.
+func (w *walker) encodeOrderedStruct(rv reflect.Value, fields []structField) ([]byte, bool, error) {
+	buf := appendUint([]byte{tagStruct}, uint64(len(fields)))
+	for _, field := range fields {
+		if w.tracing {
+			w.enter("." + field.goName)
+		}
+		data, isDigest, err := w.encodeTyped(fieldValue(rv, field))
+		w.leave()
+		if err != nil {
+			return nil, false, withPath(err, "."+field.goName)
+		}
+		name := stringToBytes(field.name)
+		buf = append(appendUint(buf, uint64(len(name))), name...)
+		buf = appendEncoded(buf, data, isDigest)
+	}
+	return buf, false, nil
+}
+
 func main() {
 	// fmt.Printf("Bool  : %x\n", ChecksumBool(Md5, true))  // Bool  : a505df1
 	// fmt.Printf("Int   : %x\n", ChecksumInt(Md5, 1))      // Int   : 1225efff
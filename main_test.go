@@ -0,0 +1,1753 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"hash/crc64"
+	"math"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	_ "time/tzdata"
+	"unsafe"
+)
+
+// hashVector is a known input/output pair of a hash function.
+type hashVector struct {
+	input    string
+	expected string // hex-encoded digest
+}
+
+func testHashVectors(t *testing.T, name string, hf HashFunc, size int, vectors []hashVector) {
+	t.Helper()
+	for _, vector := range vectors {
+		result := hf([]byte(vector.input))
+		if len(result) != size {
+			t.Errorf("%s(%q): expected %d bytes, got %d", name, vector.input, size, len(result))
+		}
+		if actual := hex.EncodeToString(result); actual != vector.expected {
+			t.Errorf("%s(%q): expected %s, got %s", name, vector.input, vector.expected, actual)
+		}
+	}
+}
+
+func TestSha256(t *testing.T) {
+	testHashVectors(t, "Sha256", Sha256, 32, []hashVector{
+		{"", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"hello world", "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"},
+	})
+}
+
+func TestSha512(t *testing.T) {
+	testHashVectors(t, "Sha512", Sha512, 64, []hashVector{
+		{"", "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e"},
+		{"abc", "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+	})
+}
+
+func TestSha512_256(t *testing.T) {
+	// FIPS 180-4 examples
+	testHashVectors(t, "Sha512_256", Sha512_256, 32, []hashVector{
+		{"", "c672b8d1ef56ed28ab87c3622c5114069bdd3ad7b8f9737498d0c01ecef0967a"},
+		{"abc", "53048e2681941ef99b2e29b76b4c7dabe4c2d0c634fc6d46e0e2f13107e7af23"},
+		{"abcdefghbcdefghicdefghijdefghijkefghijklfghijklmghijklmnhijklmnoijklmnopjklmnopqklmnopqrlmnopqrsmnopqrstnopqrstu",
+			"3928e184fb8690f840da3988121d31be65cb9d3ef83ee6146feac861e19b563a"},
+	})
+}
+
+func BenchmarkSha512_256(b *testing.B) {
+	benchmarkHashFuncs(b, 64*1024,
+		namedHashFunc{"Sha512_256", Sha512_256},
+		namedHashFunc{"Sha256", Sha256},
+	)
+}
+
+func TestChecksumSha512Struct(t *testing.T) {
+	type record struct {
+		ID   int
+		Name string
+		Tags []string
+	}
+	newRecord := func() record { return record{ID: 1, Name: "name", Tags: []string{"a", "b"}} }
+	first, second := Checksum(Sha512, newRecord()), Checksum(Sha512, newRecord())
+	if len(first) != 64 {
+		t.Fatalf("expected 64 bytes, got %d", len(first))
+	}
+	if hex.EncodeToString(first) != hex.EncodeToString(second) {
+		t.Fatalf("expected equal structs to have the same checksum, got %x and %x", first, second)
+	}
+}
+
+func TestSha1(t *testing.T) {
+	// same as `echo -n <input> | openssl sha1`
+	testHashVectors(t, "Sha1", Sha1, 20, []hashVector{
+		{"", "da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+		{"abc", "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{"The quick brown fox jumps over the lazy dog", "2fd4e1c67a2d28fced849ee1bb76e7391b93eb12"},
+	})
+}
+
+// namedHashFunc is a hash function to benchmark, see benchmarkHashFuncs.
+type namedHashFunc struct {
+	name string
+	hf   HashFunc
+}
+
+// benchmarkHashFuncs benchmarks hash functions side by side, on the same input of the specified size.
+func benchmarkHashFuncs(b *testing.B, size int, hfs ...namedHashFunc) {
+	input := make([]byte, size)
+	for i := range input {
+		input[i] = byte(i)
+	}
+	for _, nhf := range hfs {
+		b.Run(nhf.name, func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				nhf.hf(input)
+			}
+		})
+	}
+}
+
+func TestFnv1a64(t *testing.T) {
+	testHashVectors(t, "Fnv1a64", Fnv1a64, 8, []hashVector{
+		{"", "cbf29ce484222325"},
+		{"a", "af63dc4c8601ec8c"},
+	})
+	input := []byte("some input")
+	if first, second := Fnv1a64(input), Fnv1a64(input); !bytes.Equal(first, second) {
+		t.Errorf("expected Fnv1a64 to be deterministic, got %x and %x", first, second)
+	}
+	if fnv, crc := Fnv1a64(input), Crc64(input); bytes.Equal(fnv, crc) {
+		t.Errorf("expected Fnv1a64 and Crc64 to differ, both got %x", fnv)
+	}
+}
+
+func TestAdler32(t *testing.T) {
+	testHashVectors(t, "Adler32", Adler32, 4, []hashVector{
+		{"", "00000001"},
+		{"Wikipedia", "11e60398"},
+	})
+}
+
+func TestCrc32C(t *testing.T) {
+	testHashVectors(t, "Crc32C", Crc32C, 4, []hashVector{
+		{"", "00000000"},
+		{"123456789", "e3069283"},
+	})
+}
+
+func BenchmarkCrc32C(b *testing.B) {
+	// CRC32C is hardware-accelerated on CPUs with SSE4.2 (amd64) or the CRC32 extension (arm64)
+	benchmarkHashFuncs(b, 64*1024,
+		namedHashFunc{"Crc32C", Crc32C},
+		namedHashFunc{"Crc32", Crc32},
+	)
+}
+
+func TestCrc64Ecma(t *testing.T) {
+	// CRC-64/XZ check value
+	testHashVectors(t, "Crc64Ecma", Crc64Ecma, 8, []hashVector{
+		{"", "0000000000000000"},
+		{"123456789", "995dc9bbdf1939fa"},
+	})
+	testHashVectors(t, "Crc64", Crc64, 8, []hashVector{
+		{"123456789", "b90956c775a41001"},
+	})
+	input := []byte("some input")
+	if ecma, iso := Crc64Ecma(input), Crc64(input); bytes.Equal(ecma, iso) {
+		t.Errorf("expected Crc64Ecma and Crc64 to differ, both got %x", ecma)
+	}
+}
+
+func TestNewCrc32Func(t *testing.T) {
+	testHashVectors(t, "NewCrc32Func(IEEE)", NewCrc32Func(crc32.IEEE), 4, []hashVector{{"123456789", "cbf43926"}})
+	testHashVectors(t, "NewCrc32Func(Castagnoli)", NewCrc32Func(crc32.Castagnoli), 4, []hashVector{{"123456789", "e3069283"}})
+	testHashVectors(t, "NewCrc32Func(Koopman)", NewCrc32Func(crc32.Koopman), 4, []hashVector{{"123456789", "2d3dd0ae"}})
+}
+
+func TestNewCrc64Func(t *testing.T) {
+	testHashVectors(t, "NewCrc64Func(ISO)", NewCrc64Func(crc64.ISO), 8, []hashVector{{"123456789", "b90956c775a41001"}})
+	testHashVectors(t, "NewCrc64Func(ECMA)", NewCrc64Func(crc64.ECMA), 8, []hashVector{{"123456789", "995dc9bbdf1939fa"}})
+}
+
+func BenchmarkNewCrc32Func(b *testing.B) {
+	// crc32.MakeTable builds a new table on every call for polynomials other than IEEE and Castagnoli
+	rebuilding := func(input []byte) []byte {
+		hf := crc32.New(crc32.MakeTable(crc32.Koopman))
+		hf.Write(input)
+		return hf.Sum(nil)
+	}
+	benchmarkHashFuncs(b, 64,
+		namedHashFunc{"NewCrc32Func", NewCrc32Func(crc32.Koopman)},
+		namedHashFunc{"TablePerCall", rebuilding},
+	)
+}
+
+func TestChecksumPointer(t *testing.T) {
+	i := 1
+	s := "text"
+	ps := &s
+	type record struct{ Name string }
+	var nilRecord *record
+	var wrapped interface{} = 1
+	testCases := []struct {
+		name     string
+		value    interface{}
+		expected interface{}
+	}{
+		{"*int", &i, i},
+		{"**string", &ps, s},
+		{"nil *struct", nilRecord, nil},
+		{"*interface{}", &wrapped, 1},
+	}
+	for _, tc := range testCases {
+		actual, expected := Checksum(Md5, tc.value), Checksum(Md5, tc.expected)
+		if actual == nil || !bytes.Equal(actual, expected) {
+			t.Errorf("%s: expected %x, got %x", tc.name, expected, actual)
+		}
+	}
+}
+
+func TestChecksumNil(t *testing.T) {
+	var (
+		nilPointer   *int
+		nilInterface error
+		nilMap       map[string]int
+		nilSlice     []int
+	)
+	expected := Md5(nilSentinel)
+	for _, v := range []interface{}{nil, nilPointer, nilInterface, nilMap, nilSlice} {
+		if actual := Checksum(Md5, v); !bytes.Equal(actual, expected) {
+			t.Errorf("%T: expected %x, got %x", v, expected, actual)
+		}
+	}
+}
+
+func TestChecksumNumericKinds(t *testing.T) {
+	checksums := map[string][]byte{
+		"int":   Checksum(Md5, int64(1)),
+		"uint":  Checksum(Md5, uint64(1)),
+		"float": Checksum(Md5, float64(1)),
+		"bool":  Checksum(Md5, true),
+	}
+	for kind1, checksum1 := range checksums {
+		for kind2, checksum2 := range checksums {
+			if kind1 != kind2 && bytes.Equal(checksum1, checksum2) {
+				t.Errorf("expected %s and %s to have different checksums, both got %x", kind1, kind2, checksum1)
+			}
+		}
+	}
+	if !bytes.Equal(ChecksumInt(Md5, 1), checksums["int"]) || !bytes.Equal(ChecksumUint(Md5, 1), checksums["uint"]) || !bytes.Equal(ChecksumFloat(Md5, 1), checksums["float"]) {
+		t.Errorf("expected typed helpers to match Checksum")
+	}
+}
+
+func TestChecksumFloatCanonical(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	otherNaN := math.Float64frombits(0x7ff0000000000001) // signaling NaN
+	if !math.IsNaN(otherNaN) || math.Float64bits(otherNaN) == math.Float64bits(math.NaN()) {
+		t.Fatalf("expected a NaN with another bit pattern")
+	}
+	testCases := []struct {
+		name string
+		a, b float64
+		same bool
+	}{
+		{"signed zeros", 0, negZero, true},
+		{"NaNs", math.NaN(), otherNaN, true},
+		{"NaN and Inf/Inf", math.NaN(), math.Inf(1) - math.Inf(1), true},
+		{"finite values", 1.5, 2.5, false},
+		{"zero and smallest denormal", 0, math.SmallestNonzeroFloat64, false},
+		{"negative value", -1.5, 1.5, false},
+	}
+	for _, tc := range testCases {
+		if same := bytes.Equal(ChecksumFloat(Md5, tc.a), ChecksumFloat(Md5, tc.b)); same != tc.same {
+			t.Errorf("%s: expected same checksums to be %v, got %v", tc.name, tc.same, same)
+		}
+	}
+}
+
+func TestChecksumSliceBoundaries(t *testing.T) {
+	testCases := []struct {
+		name string
+		a, b interface{}
+	}{
+		{"strings", []string{"ab", "c"}, []string{"a", "bc"}},
+		{"empty first string", []string{"", "abc"}, []string{"abc", ""}},
+		{"nested slices", [][]string{{"a", "b"}, {"c"}}, [][]string{{"a"}, {"b", "c"}}},
+		{"nested vs flat", [][]int{{1, 2}}, []int{1, 2}},
+		{"byte slices", [][]byte{[]byte("ab"), []byte("c")}, [][]byte{[]byte("a"), []byte("bc")}},
+		{"order", []int{1, 2}, []int{2, 1}},
+	}
+	for _, tc := range testCases {
+		if a, b := Checksum(Md5, tc.a), Checksum(Md5, tc.b); bytes.Equal(a, b) {
+			t.Errorf("%s: expected %v and %v to have different checksums, both got %x", tc.name, tc.a, tc.b, a)
+		}
+	}
+}
+
+func TestChecksumNilVsEmptySlice(t *testing.T) {
+	var nilSlice []int
+	emptySlice := []int{}
+	if a, b := Checksum(Md5, nilSlice), Checksum(Md5, emptySlice); bytes.Equal(a, b) {
+		t.Errorf("expected nil and empty slices to have different checksums, both got %x", a)
+	}
+	if a, b := Checksum(Md5, emptySlice), Checksum(Md5, []string{}); !bytes.Equal(a, b) {
+		t.Errorf("expected empty slices to have the same checksum, got %x and %x", a, b)
+	}
+}
+
+func TestChecksumEmptyAggregates(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value interface{}
+	}{
+		{"slice", []int{}},
+		{"array", [0]int{}},
+		{"map", map[string]int{}},
+		{"struct", struct{}{}},
+	}
+	for _, tc := range testCases {
+		if checksum := Checksum(Md5, tc.value); len(checksum) != md5.Size {
+			t.Errorf("%s: expected %d bytes, got %d", tc.name, md5.Size, len(checksum))
+		}
+	}
+	slice, m, s := Checksum(Md5, []int{}), Checksum(Md5, map[string]int{}), Checksum(Md5, struct{}{})
+	if bytes.Equal(slice, m) || bytes.Equal(slice, s) || bytes.Equal(m, s) {
+		t.Errorf("expected empty slices, maps and structs to have different checksums")
+	}
+}
+
+func TestChecksumTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	instant := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	if a, b := Checksum(Md5, instant), Checksum(Md5, instant.In(newYork)); !bytes.Equal(a, b) {
+		t.Errorf("expected the same instant in different locations to have the same checksum, got %x and %x", a, b)
+	}
+	if now := time.Now(); !bytes.Equal(Checksum(Md5, now), Checksum(Md5, now.Round(0))) {
+		t.Errorf("expected the monotonic clock reading to be ignored")
+	}
+	if a, b := Checksum(Md5, instant), Checksum(Md5, instant.Add(time.Nanosecond)); bytes.Equal(a, b) {
+		t.Errorf("expected different instants to have different checksums, both got %x", a)
+	}
+}
+
+func TestChecksumBig(t *testing.T) {
+	computedOne := new(big.Int).Sub(big.NewInt(1000), big.NewInt(999))
+	testCases := []struct {
+		name string
+		a, b interface{}
+		same bool
+	}{
+		{"big.Int by arithmetic", big.NewInt(1), computedOne, true},
+		{"big.Int value and pointer", *big.NewInt(1), computedOne, true},
+		{"big.Int different", big.NewInt(1), big.NewInt(2), false},
+		{"big.Rat normalized", big.NewRat(2, 4), big.NewRat(1, 2), true},
+		{"big.Rat value and pointer", *big.NewRat(2, 4), big.NewRat(1, 2), true},
+		{"big.Rat different", big.NewRat(1, 2), big.NewRat(1, 3), false},
+		{"big.Int and int", big.NewInt(1), 1, false},
+	}
+	for _, tc := range testCases {
+		if same := bytes.Equal(Checksum(Md5, tc.a), Checksum(Md5, tc.b)); same != tc.same {
+			t.Errorf("%s: expected same checksums to be %v, got %v", tc.name, tc.same, same)
+		}
+	}
+}
+
+func TestChecksumBytes(t *testing.T) {
+	type Blob []byte
+	data := []byte("some binary data")
+	expected := Md5(append([]byte{tagBytes}, data...))
+	testCases := []struct {
+		name  string
+		value interface{}
+	}{
+		{"[]byte", data},
+		{"named byte slice", Blob(data)},
+		{"byte array", [16]byte{'s', 'o', 'm', 'e', ' ', 'b', 'i', 'n', 'a', 'r', 'y', ' ', 'd', 'a', 't', 'a'}},
+		{"pointer to byte array", &[16]byte{'s', 'o', 'm', 'e', ' ', 'b', 'i', 'n', 'a', 'r', 'y', ' ', 'd', 'a', 't', 'a'}},
+	}
+	for _, tc := range testCases {
+		if actual := Checksum(Md5, tc.value); !bytes.Equal(actual, expected) {
+			t.Errorf("%s: expected %x, got %x", tc.name, expected, actual)
+		}
+	}
+	if actual := ChecksumT(Md5, data); !bytes.Equal(actual, expected) {
+		t.Errorf("ChecksumT: expected %x, got %x", expected, actual)
+	}
+	collisions := []struct {
+		name  string
+		blob  []byte
+		value interface{}
+	}{
+		{"string", []byte("sabc"), "abc"},
+		{"int", []byte{'i', 0, 0, 0, 0, 0, 0, 0, 1}, 1},
+	}
+	for _, tc := range collisions {
+		if a, b := Checksum(Md5, tc.blob), Checksum(Md5, tc.value); bytes.Equal(a, b) {
+			t.Errorf("%s: expected blob %x and %v to have different checksums", tc.name, tc.blob, tc.value)
+		}
+	}
+}
+
+func BenchmarkChecksumBytes(b *testing.B) {
+	data := make([]byte, 1024*1024)
+	b.Run("FastPath", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			Checksum(Md5, data)
+		}
+	})
+	b.Run("Walk", func(b *testing.B) {
+		// same size, but int8 elements do not take the fast path
+		elements := make([]int8, len(data))
+		b.SetBytes(int64(len(elements)))
+		for i := 0; i < b.N; i++ {
+			Checksum(Md5, elements)
+		}
+	})
+}
+
+func TestChecksumEUnsupportedKinds(t *testing.T) {
+	type handler struct {
+		Name    string
+		Handler func()
+	}
+	var x int
+	testCases := []struct {
+		name  string
+		value interface{}
+		opts  []Option
+		path  string
+	}{
+		{"bare channel", make(chan int), nil, ""},
+		{"bare func", func() {}, nil, ""},
+		{"unsafe pointer", unsafe.Pointer(&x), nil, ""},
+		{"uintptr", uintptr(1), nil, ""},
+		{"func field", handler{Name: "h", Handler: func() {}}, []Option{WithStrictFields(true)}, "Handler"},
+		{"nested channel", map[string][]interface{}{"k": {1, make(chan int)}}, nil, `["k"][1]`},
+	}
+	for _, tc := range testCases {
+		result, err := ChecksumWith(Md5, tc.value, tc.opts...)
+		if result != nil || !errors.Is(err, ErrUnsupportedKind) {
+			t.Errorf("%s: expected ErrUnsupportedKind, got %x, %v", tc.name, result, err)
+			continue
+		}
+		var ce *ChecksumError
+		if !errors.As(err, &ce) || strings.TrimPrefix(ce.Path, ".") != tc.path {
+			t.Errorf("%s: expected path %q, got %v", tc.name, tc.path, err)
+		}
+		if len(tc.opts) == 0 && Checksum(Md5, tc.value) != nil {
+			t.Errorf("%s: expected Checksum to return nil", tc.name)
+		}
+	}
+}
+
+// cachedRecord excludes its derived cache field from its checksum.
+type cachedRecord struct {
+	ID    int
+	Cache string
+}
+
+func (r cachedRecord) Checksum(hf HashFunc) []byte {
+	return ChecksumInt(hf, int64(r.ID))
+}
+
+// pointerRecord implements Checksummer with a pointer receiver.
+type pointerRecord struct {
+	ID    int
+	Cache string
+}
+
+func (r *pointerRecord) Checksum(hf HashFunc) []byte {
+	return ChecksumInt(hf, int64(r.ID))
+}
+
+// OpaqueRecord is an exported Checksummer whose state is unexported, so that it can be embedded as an exported field.
+type OpaqueRecord struct{ id int }
+
+func (r OpaqueRecord) Checksum(hf HashFunc) []byte {
+	return ChecksumInt(hf, int64(r.id))
+}
+
+func TestChecksummer(t *testing.T) {
+	expected := ChecksumInt(Md5, 1)
+	testCases := []struct {
+		name  string
+		value interface{}
+	}{
+		{"value", cachedRecord{ID: 1, Cache: "a"}},
+		{"pointer", &cachedRecord{ID: 1, Cache: "b"}},
+		{"pointer receiver", &pointerRecord{ID: 1, Cache: "c"}},
+		{"pointer receiver by value", pointerRecord{ID: 1, Cache: "d"}},
+	}
+	for _, tc := range testCases {
+		if actual := Checksum(Md5, tc.value); !bytes.Equal(actual, expected) {
+			t.Errorf("%s: expected %x, got %x", tc.name, expected, actual)
+		}
+	}
+	nested := []struct {
+		name string
+		a, b interface{}
+	}{
+		{"slice", []cachedRecord{{1, "a"}, {2, "b"}}, []cachedRecord{{1, "c"}, {2, "d"}}},
+		{"map", map[string]cachedRecord{"k": {1, "a"}}, map[string]cachedRecord{"k": {1, "b"}}},
+		{"slice of pointer receivers", []pointerRecord{{1, "a"}}, []pointerRecord{{1, "b"}}},
+		{"map of pointer receivers", map[string]pointerRecord{"k": {1, "a"}}, map[string]pointerRecord{"k": {1, "b"}}},
+	}
+	for _, tc := range nested {
+		a, b := Checksum(Md5, tc.a), Checksum(Md5, tc.b)
+		if a == nil || !bytes.Equal(a, b) {
+			t.Errorf("%s: expected the Checksum method to be honored, got %x and %x", tc.name, a, b)
+		}
+	}
+	if a, b := Checksum(Md5, []cachedRecord{{1, "a"}}), Checksum(Md5, []cachedRecord{{2, "a"}}); bytes.Equal(a, b) {
+		t.Errorf("expected different IDs to have different checksums, both got %x", a)
+	}
+
+	// a Checksummer promoted from an embedded field is not delegated to: the struct is walked, including its other
+	// fields, and nil embedded values are never called
+	type embedding struct {
+		Checksummer
+		Y int
+	}
+	type embeddingPointer struct {
+		*cachedRecord
+		Y int
+	}
+	for _, v := range []interface{}{embedding{Y: 1}, embeddingPointer{Y: 1}} {
+		if _, err := ChecksumE(Md5, v); err != nil {
+			t.Errorf("%T with a nil embedded Checksummer: %s", v, err)
+		}
+	}
+	embedded := []struct {
+		name string
+		a, b interface{}
+	}{
+		{"interface", embedding{cachedRecord{ID: 1}, 1}, embedding{cachedRecord{ID: 1}, 2}},
+		{"pointer", embeddingPointer{&cachedRecord{ID: 1}, 1}, embeddingPointer{&cachedRecord{ID: 1}, 2}},
+		{"nil", embedding{Y: 1}, embedding{cachedRecord{ID: 1}, 1}},
+	}
+	for _, tc := range embedded {
+		if a, b := Checksum(Md5, tc.a), Checksum(Md5, tc.b); a == nil || bytes.Equal(a, b) {
+			t.Errorf("embedded %s: expected different checksums, both got %x", tc.name, a)
+		}
+	}
+	if a, b := Checksum(Md5, embedding{cachedRecord{1, "a"}, 1}), Checksum(Md5, embedding{cachedRecord{1, "b"}, 1}); !bytes.Equal(a, b) {
+		t.Errorf("expected the embedded Checksummer field itself to be honored, got %x and %x", a, b)
+	}
+}
+
+func TestChecksumSkipTag(t *testing.T) {
+	type session struct {
+		User         string
+		LastAccessed time.Time `checksum:"-"`
+	}
+	type sessionWithoutField struct {
+		User string
+	}
+	a := session{User: "alice", LastAccessed: time.Unix(1, 0)}
+	b := session{User: "alice", LastAccessed: time.Unix(2, 0)}
+	if ca, cb := Checksum(Md5, a), Checksum(Md5, b); !bytes.Equal(ca, cb) {
+		t.Errorf("expected the ignored field not to affect the checksum, got %x and %x", ca, cb)
+	}
+	if ca, cb := Checksum(Md5, a), Checksum(Md5, sessionWithoutField{User: "alice"}); !bytes.Equal(ca, cb) {
+		t.Errorf("expected the ignored field name not to affect the checksum, got %x and %x", ca, cb)
+	}
+	if ca, cb := Checksum(Md5, a), Checksum(Md5, session{User: "bob"}); bytes.Equal(ca, cb) {
+		t.Errorf("expected other fields to still affect the checksum, both got %x", ca)
+	}
+}
+
+func TestChecksumNameTag(t *testing.T) {
+	type before struct {
+		Id   int `checksum:"id"`
+		Name string
+	}
+	type after struct {
+		ID   int `checksum:"id"`
+		Name string
+	}
+	if a, b := Checksum(Md5, before{1, "x"}), Checksum(Md5, after{1, "x"}); a == nil || !bytes.Equal(a, b) {
+		t.Errorf("expected renaming a tagged field to keep the checksum, got %x and %x", a, b)
+	}
+	type untagged struct {
+		ID   int
+		Name string
+	}
+	if a, b := Checksum(Md5, after{1, "x"}), Checksum(Md5, untagged{1, "x"}); bytes.Equal(a, b) {
+		t.Errorf("expected the tag name to be mixed into the checksum, both got %x", a)
+	}
+	type duplicate struct {
+		A int `checksum:"same"`
+		B int `checksum:"same"`
+	}
+	if result, err := ChecksumE(Md5, duplicate{1, 2}); result != nil || !errors.Is(err, ErrDuplicateFieldName) {
+		t.Errorf("expected ErrDuplicateFieldName, got %x, %v", result, err)
+	}
+	type tagCollidesWithName struct {
+		A int
+		B int `checksum:"A"`
+	}
+	if _, err := ChecksumE(Md5, []tagCollidesWithName{{}}); !errors.Is(err, ErrDuplicateFieldName) {
+		t.Errorf("expected ErrDuplicateFieldName, got %v", err)
+	}
+}
+
+func TestChecksumUnexportedFields(t *testing.T) {
+	type withPrivate struct {
+		Name    string
+		private int
+	}
+	type withoutPrivate struct {
+		Name string
+	}
+	a, b := Checksum(Md5, withPrivate{"x", 1}), Checksum(Md5, withPrivate{"x", 2})
+	if a == nil || !bytes.Equal(a, b) {
+		t.Errorf("expected unexported fields to be skipped, got %x and %x", a, b)
+	}
+	if c := Checksum(Md5, withoutPrivate{"x"}); !bytes.Equal(a, c) {
+		t.Errorf("expected unexported field names to be skipped, got %x and %x", a, c)
+	}
+	if c := Checksum(Md5, withPrivate{"x", 1}); !bytes.Equal(a, c) {
+		t.Errorf("expected a stable checksum, got %x and %x", a, c)
+	}
+}
+
+type listNode struct {
+	Value int
+	Next  *listNode
+}
+
+func TestChecksumCycles(t *testing.T) {
+	newRing := func(values ...int) *listNode {
+		head := &listNode{Value: values[0]}
+		node := head
+		for _, v := range values[1:] {
+			node.Next = &listNode{Value: v}
+			node = node.Next
+		}
+		node.Next = head
+		return head
+	}
+	a, b := Checksum(Md5, newRing(1, 2, 3)), Checksum(Md5, newRing(1, 2, 3))
+	if a == nil || !bytes.Equal(a, b) {
+		t.Errorf("expected cyclic lists to have a stable checksum, got %x and %x", a, b)
+	}
+	if c := Checksum(Md5, newRing(1, 2, 4)); bytes.Equal(a, c) {
+		t.Errorf("expected different cyclic lists to have different checksums, both got %x", a)
+	}
+	self := map[string]interface{}{"k": 1}
+	self["self"] = self
+	if Checksum(Md5, self) == nil {
+		t.Errorf("expected a self-referencing map to be checksummed")
+	}
+	slice := []interface{}{1, nil}
+	slice[1] = slice
+	if Checksum(Md5, slice) == nil {
+		t.Errorf("expected a self-referencing slice to be checksummed")
+	}
+	// shared, non-cyclic references are not cycles
+	shared := &listNode{Value: 1}
+	pair := []*listNode{shared, shared}
+	if a, b := Checksum(Md5, pair), Checksum(Md5, []listNode{{Value: 1}, {Value: 1}}); !bytes.Equal(a, b) {
+		t.Errorf("expected shared references to be walked twice, got %x and %x", a, b)
+	}
+}
+
+func TestChecksumT(t *testing.T) {
+	type record struct {
+		ID   int
+		Tags []string
+	}
+	testCases := []struct {
+		name           string
+		typed, untyped []byte
+	}{
+		{"struct", ChecksumT(Md5, record{1, []string{"a"}}), Checksum(Md5, record{1, []string{"a"}})},
+		{"pointer to struct", ChecksumT(Md5, &record{1, nil}), Checksum(Md5, record{1, nil})},
+		{"string", ChecksumT(Md5, "abc"), Checksum(Md5, "abc")},
+		{"int64", ChecksumT(Md5, int64(-1)), Checksum(Md5, int64(-1))},
+		{"[]byte", ChecksumT(Md5, []byte("abc")), Checksum(Md5, []byte("abc"))},
+		{"nil []byte", ChecksumT(Md5, []byte(nil)), Checksum(Md5, nil)},
+		{"map", ChecksumT(Md5, map[string]int{"a": 1}), Checksum(Md5, map[string]int{"a": 1})},
+	}
+	for _, tc := range testCases {
+		if tc.typed == nil || !bytes.Equal(tc.typed, tc.untyped) {
+			t.Errorf("%s: expected %x, got %x", tc.name, tc.untyped, tc.typed)
+		}
+	}
+
+	// registered types take precedence over the shortcuts too
+	for _, v := range []interface{}{"abc", int64(-1), []byte("abc")} {
+		typ := reflect.TypeOf(v)
+		RegisterType(typ, func(interface{}) []byte { return []byte("registered") })
+		var typed []byte
+		switch v := v.(type) {
+		case string:
+			typed = ChecksumT(Md5, v)
+		case int64:
+			typed = ChecksumT(Md5, v)
+		case []byte:
+			typed = ChecksumT(Md5, v)
+		}
+		if untyped := Checksum(Md5, v); !bytes.Equal(typed, untyped) {
+			t.Errorf("registered %s: expected %x, got %x", typ, untyped, typed)
+		}
+		RegisterType(typ, nil)
+	}
+}
+
+func BenchmarkChecksumT(b *testing.B) {
+	data := make([]byte, 64)
+	b.Run("ChecksumT", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ChecksumT(Md5, data)
+		}
+	})
+	b.Run("Checksum", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Checksum(Md5, data)
+		}
+	})
+}
+
+// cancelingValue cancels a context when checksummed.
+type cancelingValue struct{ cancel context.CancelFunc }
+
+func (c cancelingValue) Checksum(hf HashFunc) []byte {
+	c.cancel()
+	return hf(nil)
+}
+
+func TestChecksumContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	large := make([]interface{}, 100*ctxCheckInterval)
+	for i := range large {
+		large[i] = i
+	}
+	large[10] = cancelingValue{cancel}
+	if result, err := ChecksumContext(ctx, Md5, large); result != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled mid-walk, got %x, %v", result, err)
+	}
+
+	// a context done before the walk starts aborts even small inputs
+	if result, err := ChecksumContext(ctx, Md5, 1); result != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled before the walk, got %x, %v", result, err)
+	}
+
+	v := map[string][]int{"a": {1, 2, 3}}
+	if result, err := ChecksumContext(context.Background(), Md5, v); err != nil || !bytes.Equal(result, Checksum(Md5, v)) {
+		t.Errorf("expected %x, got %x (%v)", Checksum(Md5, v), result, err)
+	}
+}
+
+// legacyTaggedUint64 is the original, bytes.Buffer based implementation of the numeric encoders.
+func legacyTaggedUint64(tag byte, v interface{}) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(tag)
+	binary.Write(buf, binary.BigEndian, v)
+	return buf.Bytes()
+}
+
+func TestNumericEncoders(t *testing.T) {
+	for _, v := range []int64{0, 1, -1, 255, math.MinInt64, math.MaxInt64} {
+		if actual, expected := intToBytes(v), legacyTaggedUint64(tagInt, v); !bytes.Equal(actual, expected) {
+			t.Errorf("intToBytes(%d): expected %x, got %x", v, expected, actual)
+		}
+	}
+	for _, v := range []uint64{0, 1, 256, math.MaxUint64} {
+		if actual, expected := uintToBytes(v), legacyTaggedUint64(tagUint, v); !bytes.Equal(actual, expected) {
+			t.Errorf("uintToBytes(%d): expected %x, got %x", v, expected, actual)
+		}
+		if actual, expected := appendUint([]byte{1}, v), append([]byte{1}, legacyTaggedUint64(tagUint, v)...); !bytes.Equal(actual, expected) {
+			t.Errorf("appendUint(%d): expected %x, got %x", v, expected, actual)
+		}
+	}
+	for _, v := range []float64{0, 1.5, -2.25, math.MaxFloat64, math.Inf(-1)} {
+		if actual, expected := floatToBytes(v), legacyTaggedUint64(tagFloat, v); !bytes.Equal(actual, expected) {
+			t.Errorf("floatToBytes(%v): expected %x, got %x", v, expected, actual)
+		}
+	}
+	buf := make([]byte, 0, 64)
+	if allocs := testing.AllocsPerRun(100, func() { appendUint(buf, math.MaxUint64) }); allocs != 0 {
+		t.Errorf("expected appendUint not to allocate, got %v allocations", allocs)
+	}
+}
+
+func BenchmarkNumericEncoders(b *testing.B) {
+	b.Run("appendUint", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make([]byte, 0, 9)
+		for i := 0; i < b.N; i++ {
+			buf = appendUint(buf[:0], uint64(i))
+		}
+	})
+	b.Run("intToBytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			intToBytes(int64(i))
+		}
+	})
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			legacyTaggedUint64(tagInt, int64(i))
+		}
+	})
+}
+
+func TestCachedStructFields(t *testing.T) {
+	type Embedded struct{ X int }
+	type record struct {
+		Embedded
+		ID      int `checksum:"id"`
+		Ignored int `checksum:"-"`
+		private int
+		Name    string
+	}
+	typ := reflect.TypeOf(record{})
+	for _, opts := range []fieldOptions{{}, {flatten: true}, {foldCase: true}} {
+		expected, _ := structFields(typ, opts)
+		for i := 0; i < 2; i++ {
+			if actual, err := cachedStructFields(typ, opts); err != nil || !reflect.DeepEqual(actual, expected) {
+				t.Errorf("%+v: expected %+v, got %+v (%v)", opts, expected, actual, err)
+			}
+		}
+	}
+	flat, _ := cachedStructFields(typ, fieldOptions{flatten: true})
+	nested, _ := cachedStructFields(typ, fieldOptions{})
+	if reflect.DeepEqual(flat, nested) {
+		t.Errorf("expected options to be part of the cache key")
+	}
+}
+
+func BenchmarkCachedStructFields(b *testing.B) {
+	typ := reflect.TypeOf(testNestedRecord)
+	b.Run("Cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			cachedStructFields(typ, fieldOptions{})
+		}
+	})
+	b.Run("Uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			structFields(typ, fieldOptions{})
+		}
+	})
+}
+
+// point implements encoding.BinaryMarshaler, ignoring its unexported cache.
+type point struct {
+	X, Y  int32
+	cache string
+}
+
+func (p point) MarshalBinary() ([]byte, error) {
+	return []byte{byte(p.X), byte(p.Y)}, nil
+}
+
+// failingMarshaler fails to marshal itself.
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalText() ([]byte, error) {
+	return nil, errors.New("marshal failed")
+}
+
+func TestChecksumMarshalers(t *testing.T) {
+	type timestamped struct {
+		time.Time
+		Name string
+	}
+	instant := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	testCases := []struct {
+		name string
+		a, b interface{}
+		same bool
+	}{
+		{"net.IP forms", net.ParseIP("192.168.0.1"), net.IP{192, 168, 0, 1}, true},
+		{"net.IP different", net.ParseIP("192.168.0.1"), net.ParseIP("192.168.0.2"), false},
+		{"net.IP and its text", net.ParseIP("192.168.0.1"), "192.168.0.1", false},
+		{"custom marshaler", point{1, 2, "a"}, point{1, 2, "b"}, true},
+		{"custom marshaler pointer", point{1, 2, "a"}, &point{1, 2, "b"}, true},
+		{"custom marshaler different", point{1, 2, ""}, point{2, 1, ""}, false},
+		{"promoted method", timestamped{instant, "a"}, timestamped{instant, "b"}, false},
+		{"promoted method via pointer", &timestamped{instant, "a"}, &timestamped{instant, "b"}, false},
+		{"promoted method and embedded time", timestamped{instant, "a"}, instant, false},
+		{"pointer receiver", *big.NewFloat(1.5), big.NewFloat(1.5), true},
+		{"pointer receiver different", *big.NewFloat(1.5), *big.NewFloat(2.5), false},
+		{"pointer receiver nested", []big.Float{*big.NewFloat(1)}, []*big.Float{big.NewFloat(1)}, true},
+	}
+	for _, tc := range testCases {
+		a, b := Checksum(Md5, tc.a), Checksum(Md5, tc.b)
+		if a == nil || b == nil || bytes.Equal(a, b) != tc.same {
+			t.Errorf("%s: expected same checksums to be %v, got %x and %x", tc.name, tc.same, a, b)
+		}
+	}
+	if expected := Md5([]byte{tagBinary, 1, 2}); !bytes.Equal(Checksum(Md5, point{1, 2, ""}), expected) {
+		t.Errorf("expected the marshaled form to be hashed")
+	}
+	if result, err := ChecksumE(Md5, []interface{}{failingMarshaler{}}); result != nil || err == nil || err.Error() != "checksum: [0]: marshal failed" {
+		t.Errorf("expected the marshal error to be returned, got %x, %v", result, err)
+	}
+}
+
+// xorCombine combines the checksums of a map's entries with XOR, like early versions did.
+func xorCombine(hf HashFunc, m map[int]int) []byte {
+	w := newWalker(context.Background(), hf, nil)
+	buf := hf([]byte{})
+	for k, v := range m {
+		temp, _ := w.checksumTuple(k, v)
+		for i := range buf {
+			buf[i] ^= temp[i]
+		}
+	}
+	return buf
+}
+
+// xorCollision builds two distinct maps that collide under xorCombine with Md5. XOR is linear: among any 129 entries
+// there is a subset whose 128-bit checksums XOR to zero, found by Gaussian elimination; splitting that subset in two
+// gives two maps with the same XOR of entry checksums.
+func xorCollision(t *testing.T) (map[int]int, map[int]int) {
+	t.Helper()
+	w := newWalker(context.Background(), Md5, nil)
+	type row struct{ vector, subset *big.Int }
+	basis := map[int]row{} // by pivot bit
+	for i := 0; i <= 8*md5.Size; i++ {
+		temp, _ := w.checksumTuple(i, i)
+		r := row{new(big.Int).SetBytes(temp), new(big.Int).SetBit(new(big.Int), i, 1)}
+		for r.vector.Sign() != 0 {
+			pivot, ok := basis[r.vector.BitLen()-1]
+			if !ok {
+				break
+			}
+			r.vector.Xor(r.vector, pivot.vector)
+			r.subset.Xor(r.subset, pivot.subset)
+		}
+		if r.vector.Sign() != 0 {
+			basis[r.vector.BitLen()-1] = r
+			continue
+		}
+		a, b := map[int]int{}, map[int]int{}
+		for j := 0; j <= i; j++ {
+			if r.subset.Bit(j) == 0 {
+				continue
+			}
+			if len(a) == 0 {
+				a[j] = j
+			} else {
+				b[j] = j
+			}
+		}
+		return a, b
+	}
+	t.Fatal("expected linearly dependent checksums")
+	return nil, nil
+}
+
+func TestChecksumXorCollisions(t *testing.T) {
+	a, b := xorCollision(t)
+	if ca, cb := Checksum(Md5, a), Checksum(Md5, b); ca == nil || bytes.Equal(ca, cb) {
+		t.Errorf("map: expected %v and %v to have different checksums, got %x and %x", a, b, ca, cb)
+	}
+	// the same entries as struct fields, and as unordered slice elements
+	type entries struct{ A, B map[int]int }
+	if ca, cb := Checksum(Md5, entries{a, b}), Checksum(Md5, entries{b, a}); bytes.Equal(ca, cb) {
+		t.Errorf("struct: expected swapped field values to have different checksums, both got %x", ca)
+	}
+	ca, _ := ChecksumWith(Md5, []map[int]int{a, a}, WithUnorderedSlices(true))
+	cb, _ := ChecksumWith(Md5, []map[int]int{b, b}, WithUnorderedSlices(true))
+	if bytes.Equal(ca, cb) {
+		t.Errorf("unordered slice: expected duplicated elements not to cancel out, both got %x", ca)
+	}
+	if a, b := Checksum(Md5, map[string]string{"a": "b"}), Checksum(Md5, map[string]string{"b": "a"}); bytes.Equal(a, b) {
+		t.Errorf("expected swapped keys and values to have different checksums, both got %x", a)
+	}
+}
+
+func TestChecksumComplex(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	testCases := []struct {
+		name string
+		a, b interface{}
+		same bool
+	}{
+		{"widths", complex(1, 2), complex64(complex(1, 2)), true},
+		{"signed zeros", complex(0, negZero), complex(0, 0), true},
+		{"NaN parts", complex(math.NaN(), 1), complex(math.Float64frombits(0x7ff0000000000001), 1), true},
+		{"swapped parts", complex(1, 2), complex(2, 1), false},
+		{"complex and float", complex(1, 0), 1.0, false},
+		{"in a slice", []complex128{complex(1, 2)}, []complex64{complex(1, 2)}, true},
+		{"in a slice, different", []complex128{complex(1, 2)}, []complex128{complex(1, 3)}, false},
+	}
+	for _, tc := range testCases {
+		a, b := Checksum(Md5, tc.a), Checksum(Md5, tc.b)
+		if a == nil || bytes.Equal(a, b) != tc.same {
+			t.Errorf("%s: expected same checksums to be %v, got %x and %x", tc.name, tc.same, a, b)
+		}
+	}
+}
+
+func TestChecksumUintptr(t *testing.T) {
+	result, err := ChecksumE(Md5, uintptr(42))
+	if result != nil || !errors.Is(err, ErrUnsupportedKind) || !strings.Contains(err.Error(), "memory addresses") {
+		t.Errorf("expected uintptr to be rejected, got %x, %v", result, err)
+	}
+	if Checksum(Md5, uint64(42)) == nil {
+		t.Errorf("expected uint64 to be checksummed")
+	}
+}
+
+func TestChecksumNilInterfaceField(t *testing.T) {
+	type envelope struct {
+		Data interface{}
+	}
+	empty, filled := Checksum(Md5, envelope{}), Checksum(Md5, envelope{Data: "x"})
+	if empty == nil || bytes.Equal(empty, filled) {
+		t.Errorf("expected a nil interface field to differ from a non-nil one, got %x and %x", empty, filled)
+	}
+	if expected := Checksum(Md5, struct{ Data *int }{}); !bytes.Equal(empty, expected) {
+		t.Errorf("expected a nil interface field to hash like any nil field, got %x instead of %x", empty, expected)
+	}
+	type other struct {
+		Other interface{}
+	}
+	if actual := Checksum(Md5, other{}); bytes.Equal(empty, actual) {
+		t.Errorf("expected the field name to be mixed in, both got %x", empty)
+	}
+	var nilError error
+	if a, b := Checksum(Md5, envelope{nilError}), Checksum(Md5, envelope{(*int)(nil)}); !bytes.Equal(a, empty) || !bytes.Equal(b, empty) {
+		t.Errorf("expected nil values of any type to hash like a nil interface field")
+	}
+}
+
+func TestCombine(t *testing.T) {
+	a, b := Checksum(Sha256, "page 1"), Checksum(Sha256, "page 2")
+	ab := Combine(Sha256, a, b)
+	if len(ab) != 32 || !bytes.Equal(ab, Combine(Sha256, a, b)) {
+		t.Errorf("expected a stable 32-byte checksum, got %x", ab)
+	}
+	if ba := Combine(Sha256, b, a); bytes.Equal(ab, ba) {
+		t.Errorf("expected Combine to be order-sensitive, both got %x", ab)
+	}
+	if c := Combine(Sha256, append(append([]byte{}, a...), b...)); bytes.Equal(ab, c) {
+		t.Errorf("expected digest boundaries to be unambiguous, both got %x", ab)
+	}
+	if x, y := Combine(Sha256), Combine(Sha256, []byte{}); bytes.Equal(x, y) {
+		t.Errorf("expected no digests and one empty digest to differ, both got %x", x)
+	}
+}
+
+func TestArrayFuncs(t *testing.T) {
+	for _, input := range []string{"", "abc", "The quick brown fox jumps over the lazy dog"} {
+		data := []byte(input)
+		md5Array, crc32Array, crc64Array := Md5Array(data), Crc32Array(data), Crc64Array(data)
+		if !bytes.Equal(md5Array[:], Md5(data)) {
+			t.Errorf("Md5Array(%q): expected %x, got %x", input, Md5(data), md5Array)
+		}
+		if !bytes.Equal(crc32Array[:], Crc32(data)) {
+			t.Errorf("Crc32Array(%q): expected %x, got %x", input, Crc32(data), crc32Array)
+		}
+		if !bytes.Equal(crc64Array[:], Crc64(data)) {
+			t.Errorf("Crc64Array(%q): expected %x, got %x", input, Crc64(data), crc64Array)
+		}
+	}
+	seen := map[[md5.Size]byte]bool{Md5Array([]byte("abc")): true}
+	if !seen[Md5Array([]byte("abc"))] {
+		t.Errorf("expected arrays to be usable as map keys")
+	}
+}
+
+func TestChecksumPointerToAggregate(t *testing.T) {
+	array := [3]int{1, 2, 3}
+	slice := []string{"a", "b"}
+	m := map[string]int{"a": 1}
+	testCases := []struct {
+		name       string
+		ptr, value interface{}
+	}{
+		{"*[3]int", &array, array},
+		{"*[]string", &slice, slice},
+		{"*map[string]int", &m, m},
+		{"nil *[3]int", (*[3]int)(nil), nil},
+		{"nil *[]string", (*[]string)(nil), nil},
+		{"nil *map[string]int", (*map[string]int)(nil), nil},
+	}
+	for _, tc := range testCases {
+		if a, b := Checksum(Md5, tc.ptr), Checksum(Md5, tc.value); a == nil || !bytes.Equal(a, b) {
+			t.Errorf("%s: expected %x, got %x", tc.name, b, a)
+		}
+	}
+	// arrays are hashed by content, not address
+	before := Checksum(Md5, &array)
+	array[0] = 10
+	if after := Checksum(Md5, &array); bytes.Equal(before, after) {
+		t.Errorf("expected modifying the array to change the checksum, both got %x", before)
+	}
+}
+
+func TestChecksumSliceSinglePass(t *testing.T) {
+	calls := 0
+	counting := func(input []byte) []byte {
+		calls++
+		return Md5(input)
+	}
+	values := make([]int, 1000)
+	for i := range values {
+		values[i] = i
+	}
+	expected := Checksum(counting, values)
+	if calls != 1 {
+		t.Errorf("expected the slice to be hashed once, got %d calls", calls)
+	}
+	for _, i := range []int{0, 500, 999} {
+		changed := append([]int(nil), values...)
+		changed[i]++
+		if actual := Checksum(Md5, changed); bytes.Equal(actual, expected) {
+			t.Errorf("expected changing element %d to change the checksum", i)
+		}
+	}
+	swapped := append([]int(nil), values...)
+	swapped[1], swapped[2] = swapped[2], swapped[1]
+	if actual := Checksum(Md5, swapped); bytes.Equal(actual, expected) {
+		t.Errorf("expected changing the order to change the checksum")
+	}
+	if actual := Checksum(Md5, values[:999]); bytes.Equal(actual, expected) {
+		t.Errorf("expected removing an element to change the checksum")
+	}
+	// elements that are checksummed by a Checksummer are told apart from serialized forms
+	if a, b := Checksum(Md5, []interface{}{cachedRecord{ID: 1}}), Checksum(Md5, []interface{}{ChecksumInt(Md5, 1)}); bytes.Equal(a, b) {
+		t.Errorf("expected a Checksummer result and a blob with the same bytes to differ, both got %x", a)
+	}
+}
+
+func BenchmarkChecksumSlice(b *testing.B) {
+	values := make([]int, 100000)
+	for i := range values {
+		values[i] = i
+	}
+	b.Run("SinglePass", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Checksum(Md5, values)
+		}
+	})
+	b.Run("PerElement", func(b *testing.B) {
+		// how slices used to be checksummed: every element on its own, then the checksums combined
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			digests := make([][]byte, len(values))
+			for j, v := range values {
+				digests[j] = Checksum(Md5, v)
+			}
+			Combine(Md5, digests...)
+		}
+	})
+}
+
+func TestChecksumSyncMap(t *testing.T) {
+	var m sync.Map
+	builtin := map[interface{}]interface{}{}
+	for i := 0; i < 100; i++ {
+		m.Store(fmt.Sprint(i), i)
+		builtin[fmt.Sprint(i)] = i
+	}
+	expected := Checksum(Md5, builtin)
+	if actual := Checksum(Md5, &m); !bytes.Equal(actual, expected) {
+		t.Errorf("expected %x, got %x", expected, actual)
+	}
+	if actual := Checksum(Md5, map[string]int{"0": 0}); bytes.Equal(actual, expected) {
+		t.Errorf("expected different entries to have different checksums")
+	}
+	type holder struct{ M *sync.Map }
+	if a, b := Checksum(Md5, holder{&m}), Checksum(Md5, struct{ M map[interface{}]interface{} }{builtin}); !bytes.Equal(a, b) {
+		t.Errorf("expected a nested sync.Map to equal the built-in map, got %x and %x", a, b)
+	}
+
+	var self sync.Map
+	self.Store("self", &self)
+	a, b := Checksum(Md5, &self), Checksum(Md5, &self)
+	if a == nil || !bytes.Equal(a, b) {
+		t.Errorf("expected a self-referencing sync.Map to have a stable checksum, got %x and %x", a, b)
+	}
+}
+
+// TestChecksumSyncMapConcurrent is meant to be run with -race: sync.Map values reached through a pointer must be
+// walked in place, as copying them races with concurrent writers.
+func TestChecksumSyncMapConcurrent(t *testing.T) {
+	type nested struct{ Inner sync.Map }
+	type holder struct {
+		M      sync.Map
+		Nested nested
+		Array  [2]sync.Map
+		List   []nested
+	}
+	h := &holder{List: make([]nested, 2)}
+	writers := []*sync.Map{&h.M, &h.Nested.Inner, &h.Array[1], &h.List[0].Inner}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, m := range writers {
+		wg.Add(1)
+		go func(m *sync.Map) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+					m.Store(i%10, i)
+				}
+			}
+		}(m)
+	}
+	for i := 0; i < 100; i++ {
+		for _, v := range []interface{}{h, &h.Nested, &h.Array, h.List, &h.List} {
+			if _, err := ChecksumE(Md5, v); err != nil {
+				t.Errorf("%T: %s", v, err)
+			}
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	// once the writers are done, the checksum equals that of the built-in maps with the same entries
+	entries := func(m *sync.Map) map[interface{}]interface{} {
+		result := map[interface{}]interface{}{}
+		m.Range(func(k, v interface{}) bool {
+			result[k] = v
+			return true
+		})
+		return result
+	}
+	type builtinNested struct{ Inner map[interface{}]interface{} }
+	expected := Checksum(Md5, struct {
+		M      map[interface{}]interface{}
+		Nested builtinNested
+		Array  [2]map[interface{}]interface{}
+		List   []builtinNested
+	}{
+		entries(&h.M),
+		builtinNested{entries(&h.Nested.Inner)},
+		[2]map[interface{}]interface{}{entries(&h.Array[0]), entries(&h.Array[1])},
+		[]builtinNested{{entries(&h.List[0].Inner)}, {entries(&h.List[1].Inner)}},
+	})
+	if actual := Checksum(Md5, h); !bytes.Equal(actual, expected) {
+		t.Errorf("expected %x, got %x", expected, actual)
+	}
+}
+
+func TestChecksumCyclesThroughAggregates(t *testing.T) {
+	newSlice := func() []interface{} {
+		s := make([]interface{}, 2)
+		s[0] = 1
+		s[1] = &s
+		return s
+	}
+	a, b := Checksum(Md5, newSlice()), Checksum(Md5, newSlice())
+	if a == nil || !bytes.Equal(a, b) {
+		t.Errorf("slice: expected a stable checksum, got %x and %x", a, b)
+	}
+
+	type container struct {
+		Name     string
+		Children map[string]*container
+	}
+	newContainer := func(name string) *container {
+		c := &container{Name: name, Children: map[string]*container{}}
+		c.Children["self"] = c
+		c.Children["other"] = &container{Name: "leaf", Children: map[string]*container{"parent": c}}
+		return c
+	}
+	a, b = Checksum(Md5, newContainer("root")), Checksum(Md5, newContainer("root"))
+	if a == nil || !bytes.Equal(a, b) {
+		t.Errorf("map: expected a stable checksum, got %x and %x", a, b)
+	}
+	if c := Checksum(Md5, newContainer("other root")); bytes.Equal(a, c) {
+		t.Errorf("map: expected different containers to have different checksums, both got %x", a)
+	}
+}
+
+// mutatingRecord implements Checksummer with a pointer receiver that modifies the receiver.
+type mutatingRecord struct{ Calls int }
+
+func (r *mutatingRecord) Checksum(hf HashFunc) []byte {
+	r.Calls++
+	return ChecksumInt(hf, 0)
+}
+
+func TestChecksummerReceivers(t *testing.T) {
+	var wrappedValue interface{} = cachedRecord{ID: 1}
+	var wrappedPointerReceiver interface{} = pointerRecord{ID: 1}
+	for name, v := range map[string]interface{}{"value receiver": &wrappedValue, "pointer receiver": &wrappedPointerReceiver} {
+		if actual, expected := Checksum(Md5, v), ChecksumInt(Md5, 1); !bytes.Equal(actual, expected) {
+			t.Errorf("%s, *interface{}: expected %x, got %x", name, expected, actual)
+		}
+	}
+	// both implementations return the same checksum, which is all that is hashed of them
+	type holder struct{ Value interface{} }
+	testCases := []struct {
+		name string
+		a, b interface{}
+	}{
+		{"interface{} field", holder{cachedRecord{1, "a"}}, holder{pointerRecord{1, "b"}}},
+		{"[]interface{}", []interface{}{cachedRecord{1, "a"}}, []interface{}{pointerRecord{1, "b"}}},
+		{"map[string]interface{}", map[string]interface{}{"k": cachedRecord{1, "a"}}, map[string]interface{}{"k": &pointerRecord{1, "b"}}},
+	}
+	for _, tc := range testCases {
+		if a, b := Checksum(Md5, tc.a), Checksum(Md5, tc.b); a == nil || !bytes.Equal(a, b) {
+			t.Errorf("%s: expected both methods to be honored, got %x and %x", tc.name, a, b)
+		}
+	}
+
+	// values passed by value are not addressable: the method is called on a copy
+	record := mutatingRecord{}
+	Checksum(Md5, record)
+	if record.Calls != 0 {
+		t.Errorf("expected the caller's value not to be modified, got %d calls", record.Calls)
+	}
+	Checksum(Md5, &record)
+	if record.Calls != 1 {
+		t.Errorf("expected the method to be called on the pointer passed, got %d calls", record.Calls)
+	}
+}
+
+// ptrStringer and ptrError implement their interfaces with pointer receivers only.
+type ptrStringer struct{ S string }
+
+func (p *ptrStringer) String() string { return "stringer:" + p.S }
+
+type ptrError struct{ Msg string }
+
+func (e *ptrError) Error() string { return e.Msg }
+
+func TestChecksumPointerValueMatrix(t *testing.T) {
+	one, two, s := 1, 2, "a"
+	f := *big.NewFloat(1.5)
+	nilMap, nilSlice := map[string]int(nil), []int(nil)
+	record := pointerRecord{ID: 7, Cache: "x"}
+	stringer, perr := ptrStringer{S: "a"}, ptrError{Msg: "failed"}
+	pairs := []struct {
+		name       string
+		ptr, value interface{}
+	}{
+		{"*int", &one, one},
+		{"struct fields", struct {
+			A *int
+			B *string
+		}{&one, &s}, struct {
+			A int
+			B string
+		}{one, s}},
+		{"nested struct fields", struct{ Inner struct{ P *int } }{struct{ P *int }{&two}},
+			struct{ Inner struct{ P int } }{struct{ P int }{two}}},
+		{"slice of pointers", []*point{{X: 1, Y: 2}, {X: 3}}, []point{{X: 1, Y: 2}, {X: 3}}},
+		{"map of pointers", map[string]*int{"a": &one, "b": &two}, map[string]int{"a": one, "b": two}},
+		{"slice of maps of pointers", []map[string]*int{{"a": &one}}, []map[string]int{{"a": one}}},
+		{"*big.Float", &f, f},
+		{"*Checksummer", &record, record},
+		{"*fmt.Stringer", &stringer, stringer},
+		{"*error", &perr, perr},
+		{"*map nil", &nilMap, nilMap},
+		{"*slice nil", &nilSlice, nilSlice},
+		{"**struct", func() interface{} { p := &stringer; return &p }(), stringer},
+	}
+	options := []struct {
+		name string
+		opts []Option
+	}{
+		{"default", nil},
+		{"WithTypeTags", []Option{WithTypeTags(true)}},
+		{"WithStringer", []Option{WithStringer(true)}},
+		{"WithOrderedStruct", []Option{WithOrderedStruct(true)}},
+		{"WithSortedMap", []Option{WithSortedMap(true)}},
+		{"WithUnorderedSlices", []Option{WithUnorderedSlices(true)}},
+		{"all", []Option{WithTypeTags(true), WithStringer(true), WithOrderedStruct(true), WithSortedMap(true),
+			WithUnorderedSlices(true)}},
+	}
+	for _, o := range options {
+		for _, p := range pairs {
+			expected, err := ChecksumWith(Md5, p.value, o.opts...)
+			if err != nil {
+				t.Errorf("%s/%s: %s", o.name, p.name, err)
+				continue
+			}
+			if actual, _ := ChecksumWith(Md5, p.ptr, o.opts...); !bytes.Equal(actual, expected) {
+				t.Errorf("%s/%s: expected %x, got %x", o.name, p.name, expected, actual)
+			}
+		}
+	}
+	// pointer-receiver methods are honored for values, rather than the values being walked
+	if expected, actual := Checksum(Md5, errors.New("failed")), Checksum(Md5, perr); !bytes.Equal(actual, expected) {
+		t.Errorf("*error: expected %x, got %x", expected, actual)
+	}
+	if a, _ := ChecksumWith(Md5, stringer, WithStringer(true)); bytes.Equal(a, Checksum(Md5, stringer)) {
+		t.Errorf("*fmt.Stringer: expected String() to be checksummed under WithStringer")
+	}
+}
+
+// wrapInterface returns v wrapped in depth levels of *interface{}, built through reflection.
+func wrapInterface(v interface{}, depth int) interface{} {
+	ifaceType := reflect.TypeOf((*interface{})(nil)).Elem()
+	for i := 0; i < depth; i++ {
+		wrapper := reflect.New(ifaceType)
+		if v != nil {
+			wrapper.Elem().Set(reflect.ValueOf(v))
+		}
+		v = wrapper.Interface()
+	}
+	return v
+}
+
+func TestChecksumNestedInterfaces(t *testing.T) {
+	values := []interface{}{42, "abc", []int{1, 2}, map[string]int{"a": 1}, point{X: 1, Y: 2}, nil}
+	for _, value := range values {
+		expected := Checksum(Md5, value)
+		for depth := 1; depth <= 3; depth++ {
+			wrapped := wrapInterface(value, depth)
+			if actual := Checksum(Md5, wrapped); !bytes.Equal(actual, expected) {
+				t.Errorf("%v at depth %d: expected %x, got %x", value, depth, expected, actual)
+			}
+			// nested inside aggregates too
+			if a, b := Checksum(Md5, []interface{}{wrapped}), Checksum(Md5, []interface{}{value}); !bytes.Equal(a, b) {
+				t.Errorf("%v at depth %d in a slice: expected %x, got %x", value, depth, b, a)
+			}
+			if a, b := Checksum(Md5, struct{ F interface{} }{wrapped}), Checksum(Md5, struct{ F interface{} }{value}); !bytes.Equal(a, b) {
+				t.Errorf("%v at depth %d in a struct: expected %x, got %x", value, depth, b, a)
+			}
+		}
+	}
+}
+
+func TestChecksumTupleBoundaries(t *testing.T) {
+	// the raw concatenations of these pairs are equal, so they would collide without length framing
+	if !bytes.Equal(Md5([]byte("ab"+"c")), Md5([]byte("a"+"bc"))) {
+		t.Fatalf("expected the raw concatenations to collide")
+	}
+	type abField struct {
+		F string `checksum:"ab"`
+	}
+	type aField struct {
+		F string `checksum:"a"`
+	}
+	testCases := []struct {
+		name string
+		a, b interface{}
+	}{
+		{"map entries", map[string]string{"ab": "c"}, map[string]string{"a": "bc"}},
+		{"struct fields", abField{"c"}, aField{"bc"}},
+		{"tuples", []interface{}{"ab", "c"}, []interface{}{"a", "bc"}},
+	}
+	for _, tc := range testCases {
+		if a, b := Checksum(Md5, tc.a), Checksum(Md5, tc.b); bytes.Equal(a, b) {
+			t.Errorf("%s: expected %v and %v to have different checksums, both got %x", tc.name, tc.a, tc.b, a)
+		}
+	}
+	// also with the options using other encodings of maps and structs
+	for _, opt := range []Option{WithSortedMap(true), WithOrderedStruct(true)} {
+		for _, tc := range testCases[:2] {
+			a, _ := ChecksumWith(Md5, tc.a, opt)
+			if b, _ := ChecksumWith(Md5, tc.b, opt); bytes.Equal(a, b) {
+				t.Errorf("%s: expected %v and %v to have different checksums, both got %x", tc.name, tc.a, tc.b, a)
+			}
+		}
+	}
+}
+
+func TestChecksumErrors(t *testing.T) {
+	type result struct {
+		Value int
+		Err   error
+	}
+	boom, err := ChecksumE(Md5, result{1, errors.New("boom")})
+	if err != nil {
+		t.Fatalf("error field: %s", err)
+	}
+	ok, err := ChecksumE(Md5, result{1, nil})
+	if err != nil {
+		t.Fatalf("nil error field: %s", err)
+	}
+	if bytes.Equal(boom, ok) {
+		t.Errorf("expected a nil error and a non-nil error to have different checksums, both got %x", boom)
+	}
+	// errors are checksummed by their message, whatever their concrete types
+	testCases := []struct {
+		name string
+		a, b interface{}
+	}{
+		{"errors.New", errors.New("boom"), errors.New("boom")},
+		{"fmt.Errorf", fmt.Errorf("boom"), errors.New("boom")},
+		{"wrapped", fmt.Errorf("walk: %w", ErrUnsupportedKind), errors.New("walk: " + ErrUnsupportedKind.Error())},
+		{"field", result{1, fmt.Errorf("boom")}, result{1, errors.New("boom")}},
+	}
+	for _, tc := range testCases {
+		if a, b := Checksum(Md5, tc.a), Checksum(Md5, tc.b); a == nil || !bytes.Equal(a, b) {
+			t.Errorf("%s: expected %x, got %x", tc.name, b, a)
+		}
+	}
+	if a, b := Checksum(Md5, errors.New("boom")), Checksum(Md5, errors.New("bang")); bytes.Equal(a, b) {
+		t.Errorf("expected different messages to have different checksums, both got %x", a)
+	}
+	if a, b := Checksum(Md5, errors.New("boom")), Checksum(Md5, "boom"); bytes.Equal(a, b) {
+		t.Errorf("expected an error to differ from its message as a string, both got %x", a)
+	}
+	// a struct embedding an error is walked rather than checksummed by the message alone, even if the error is nil
+	type failure struct {
+		error
+		Code int
+	}
+	failed, err := ChecksumE(Md5, failure{Code: 1})
+	if err != nil {
+		t.Fatalf("nil embedded error: %s", err)
+	}
+	if a, b := Checksum(Md5, failure{errors.New("boom"), 1}), Checksum(Md5, failure{errors.New("boom"), 2}); bytes.Equal(a, b) {
+		t.Errorf("expected fields next to an embedded error to be checksummed, both got %x", a)
+	}
+	if a, b := Checksum(Md5, failure{errors.New("boom"), 1}), Checksum(Md5, errors.New("boom")); bytes.Equal(a, b) {
+		t.Errorf("expected a struct embedding an error to differ from the error, both got %x", a)
+	}
+	if a := Checksum(Md5, &failure{Code: 1}); !bytes.Equal(a, failed) {
+		t.Errorf("nil embedded error via pointer: expected %x, got %x", failed, a)
+	}
+}
+
+func TestChecksumNumericWidths(t *testing.T) {
+	families := []struct {
+		name   string
+		values []interface{}
+	}{
+		{"int", []interface{}{int(1), int8(1), int16(1), int32(1), int64(1)}},
+		{"uint", []interface{}{uint(1), uint8(1), uint16(1), uint32(1), uint64(1)}},
+		{"float", []interface{}{float32(1), float64(1)}},
+	}
+	for _, family := range families {
+		expected := Checksum(Md5, family.values[0])
+		for _, v := range family.values[1:] {
+			if actual := Checksum(Md5, v); !bytes.Equal(actual, expected) {
+				t.Errorf("%T: expected %x (%T), got %x", v, expected, family.values[0], actual)
+			}
+			// so that a field can be widened without changing the checksum of its struct
+			if a, b := Checksum(Md5, struct{ F interface{} }{v}), Checksum(Md5, struct{ F interface{} }{family.values[0]}); !bytes.Equal(a, b) {
+				t.Errorf("%T field: expected %x, got %x", v, b, a)
+			}
+		}
+	}
+	// the families remain distinct, and WithTypeTags distinguishes widths too
+	for i, family := range families {
+		for _, other := range families[i+1:] {
+			if a, b := Checksum(Md5, family.values[0]), Checksum(Md5, other.values[0]); bytes.Equal(a, b) {
+				t.Errorf("expected %s and %s to have different checksums, both got %x", family.name, other.name, a)
+			}
+		}
+		seen := map[string]string{}
+		for _, v := range family.values {
+			checksum, _ := ChecksumWith(Md5, v, WithTypeTags(true))
+			if previous, ok := seen[string(checksum)]; ok {
+				t.Errorf("WithTypeTags: expected %s and %T to have different checksums, both got %x", previous, v, checksum)
+			}
+			seen[string(checksum)] = fmt.Sprintf("%T", v)
+		}
+	}
+}
+
+func testEachValues(n int) []interface{} {
+	values := make([]interface{}, n)
+	for i := range values {
+		values[i] = cachedRecord{ID: i, Cache: "x"}
+		if i%2 == 0 {
+			values[i] = map[string]interface{}{"id": i, "name": fmt.Sprintf("record-%d", i)}
+		}
+	}
+	return values
+}
+
+func TestChecksumEach(t *testing.T) {
+	values := append(testEachValues(100), make(chan int), nil, "after an error", []int{1, 2})
+	results := ChecksumEach(Md5, values)
+	if len(results) != len(values) {
+		t.Fatalf("expected %d checksums, got %d", len(values), len(results))
+	}
+	for i, v := range values {
+		if expected := Checksum(Md5, v); !bytes.Equal(results[i], expected) {
+			t.Errorf("value %d (%v): expected %x, got %x", i, v, expected, results[i])
+		}
+	}
+	if results[100] != nil {
+		t.Errorf("expected nil for a value that can not be checksummed, got %x", results[100])
+	}
+	if combined := Checksum(Md5, values[:2]); bytes.Equal(combined, results[0]) || bytes.Equal(combined, results[1]) {
+		t.Errorf("expected the aggregate checksum to differ from the individual ones")
+	}
+	if results := ChecksumEach(Md5, nil); len(results) != 0 {
+		t.Errorf("expected no checksums, got %d", len(results))
+	}
+}
+
+func BenchmarkChecksumEach(b *testing.B) {
+	values := testEachValues(10000)
+	b.Run("ChecksumEach", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ChecksumEach(Sha256, values)
+		}
+	})
+	b.Run("Checksum", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			results := make([][]byte, len(values))
+			for j, v := range values {
+				results[j] = Checksum(Sha256, v)
+			}
+		}
+	})
+}
+
+func TestChecksumDuration(t *testing.T) {
+	type withDuration struct{ Timeout time.Duration }
+	type withInt struct{ Timeout int64 }
+	for _, opts := range [][]Option{nil, {WithTypeTags(true)}} {
+		d, _ := ChecksumWith(Md5, 5*time.Second, opts...)
+		i, _ := ChecksumWith(Md5, int64(5000000000), opts...)
+		if d == nil || bytes.Equal(d, i) {
+			t.Errorf("%d options: expected 5s and int64(5000000000) to differ, both got %x", len(opts), d)
+		}
+		a, _ := ChecksumWith(Md5, withDuration{5 * time.Second}, opts...)
+		b, _ := ChecksumWith(Md5, withInt{5000000000}, opts...)
+		if bytes.Equal(a, b) {
+			t.Errorf("%d options: expected the fields to differ, both got %x", len(opts), a)
+		}
+		// deterministic, and by value
+		again, _ := ChecksumWith(Md5, time.Duration(5000000000), opts...)
+		ptr, _ := ChecksumWith(Md5, func() *time.Duration { d := 5 * time.Second; return &d }(), opts...)
+		if !bytes.Equal(d, again) || !bytes.Equal(d, ptr) {
+			t.Errorf("%d options: expected %x, got %x and %x", len(opts), d, again, ptr)
+		}
+		if other, _ := ChecksumWith(Md5, 6*time.Second, opts...); bytes.Equal(d, other) {
+			t.Errorf("%d options: expected 5s and 6s to differ, both got %x", len(opts), d)
+		}
+	}
+	if expected, actual := Md5(taggedUint64(tagDuration, uint64(time.Minute))), Checksum(Md5, time.Minute); !bytes.Equal(actual, expected) {
+		t.Errorf("expected %x, got %x", expected, actual)
+	}
+}
+
+type exportedInner struct {
+	X      int
+	secret string
+}
+
+type exportedMiddle struct {
+	exportedInner
+	Z      string
+	hidden []int
+}
+
+type ExportedBase struct {
+	ID int
+}
+
+func TestChecksumExported(t *testing.T) {
+	type outer struct {
+		exportedInner
+		Y int
+	}
+	type deep struct {
+		exportedMiddle
+		Y int
+	}
+	type byPointer struct {
+		*exportedInner
+		Y int
+	}
+	type shadowing struct {
+		exportedInner
+		X int
+	}
+	type exportedEmbedded struct {
+		ExportedBase
+		Y int
+	}
+	type nested struct {
+		Name  string
+		Child outer
+		List  []deep
+		note  string
+	}
+	type flatXY struct{ X, Y int }
+	type flatXYZ struct {
+		X int
+		Z string
+		Y int
+	}
+	type flatNested struct {
+		Name  string
+		Child flatXY
+		List  []flatXYZ
+	}
+	testCases := []struct {
+		name     string
+		v        interface{}
+		expected interface{}
+	}{
+		{"one level", outer{exportedInner{1, "s"}, 2}, flatXY{1, 2}},
+		{"two levels", deep{exportedMiddle{exportedInner{1, "s"}, "z", []int{1}}, 2}, flatXYZ{1, "z", 2}},
+		{"pointer", byPointer{&exportedInner{1, "s"}, 2}, flatXY{1, 2}},
+		{"nil pointer", byPointer{nil, 2}, struct{ X, Y interface{} }{nil, 2}},
+		{"shadowed", shadowing{exportedInner{1, "s"}, 2}, struct{ X int }{2}},
+		{"exported embedded", exportedEmbedded{ExportedBase{1}, 2}, struct {
+			ExportedBase ExportedBase
+			Y            int
+		}{ExportedBase{1}, 2}},
+		{"nested", nested{"n", outer{exportedInner{1, "a"}, 2}, []deep{{exportedMiddle{exportedInner{3, "b"}, "z", nil}, 4}}, "x"},
+			flatNested{"n", flatXY{1, 2}, []flatXYZ{{3, "z", 4}}}},
+	}
+	for _, tc := range testCases {
+		actual, err := ChecksumExported(Md5, tc.v)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		if expected := Checksum(Md5, tc.expected); !bytes.Equal(actual, expected) {
+			t.Errorf("%s: expected %x, got %x", tc.name, expected, actual)
+		}
+	}
+
+	// promoted fields are checksummed, while unexported fields at every level are excluded
+	base := deep{exportedMiddle{exportedInner{1, "s"}, "z", []int{1}}, 2}
+	expected, _ := ChecksumExported(Md5, base)
+	changedUnexported := deep{exportedMiddle{exportedInner{1, "other"}, "z", []int{2, 3}}, 2}
+	if actual, _ := ChecksumExported(Md5, changedUnexported); !bytes.Equal(actual, expected) {
+		t.Errorf("expected unexported fields to be ignored: expected %x, got %x", expected, actual)
+	}
+	for _, changed := range []deep{
+		{exportedMiddle{exportedInner{9, "s"}, "z", []int{1}}, 2},
+		{exportedMiddle{exportedInner{1, "s"}, "changed", []int{1}}, 2},
+	} {
+		if actual, _ := ChecksumExported(Md5, changed); bytes.Equal(actual, expected) {
+			t.Errorf("%+v: expected changing a promoted field to change the checksum", changed)
+		}
+	}
+	// unlike ChecksumE, which drops unexported embedded structs altogether
+	if a, b := Checksum(Md5, outer{exportedInner{1, "s"}, 2}), Checksum(Md5, outer{exportedInner{9, "s"}, 2}); !bytes.Equal(a, b) {
+		t.Errorf("expected ChecksumE to ignore unexported embedded structs, got %x and %x", a, b)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ChecksumMap calculates checksum of a map (or pointer to map, possibly nil) of any type, the same way Checksum does:
+// the checksums of its entries are sorted before being combined, so the result does not depend on insertion or
+// iteration order, and entries with equal checksums do not cancel each other out (unlike XOR-combining them).
+//
+// It returns a *ChecksumError if v is not a map, or if any key or value can not be checksummed.
+func ChecksumMap(hf HashFunc, m interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(m)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			// a nil pointer to a map is checksummed like a nil map
+			rv = reflect.Zero(rv.Type().Elem())
+		} else {
+			rv = rv.Elem()
+		}
+	}
+	if rv.Kind() != reflect.Map {
+		return nil, &ChecksumError{Err: fmt.Errorf("expected a map, got %s", rv.Kind())}
+	}
+	return ChecksumE(hf, rv.Interface())
+}
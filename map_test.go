@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestChecksumMap(t *testing.T) {
+	type entry struct {
+		Name  string
+		Score int
+	}
+	forward := map[string]int{}
+	backward := map[string]int{}
+	for i, k := range []string{"a", "b", "c", "d"} {
+		forward[k] = i
+	}
+	for i, k := range []string{"d", "c", "b", "a"} {
+		backward[k] = 3 - i
+	}
+	structs := map[int]entry{1: {"a", 10}, 2: {"b", 20}}
+	testCases := []struct {
+		name  string
+		a, b  interface{}
+		equal bool
+	}{
+		{"insertion order", forward, backward, true},
+		{"pointer to map", &forward, forward, true},
+		{"struct values", structs, map[int]entry{2: {"b", 20}, 1: {"a", 10}}, true},
+		{"changed value", forward, map[string]int{"a": 0, "b": 1, "c": 2, "d": 4}, false},
+		{"changed struct field", structs, map[int]entry{1: {"a", 10}, 2: {"b", 21}}, false},
+		{"swapped values", map[string]int{"a": 1, "b": 2}, map[string]int{"a": 2, "b": 1}, false},
+		{"duplicate entries do not cancel", map[string]int{"a": 1, "b": 1}, map[string]int{}, false},
+		{"nil pointer to map", (*map[string]int)(nil), map[string]int(nil), true},
+	}
+	for _, tc := range testCases {
+		a, err := ChecksumMap(Md5, tc.a)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		b, err := ChecksumMap(Md5, tc.b)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		if bytes.Equal(a, b) != tc.equal {
+			t.Errorf("%s: expected equal=%v, got %x and %x", tc.name, tc.equal, a, b)
+		}
+		if expected := Checksum(Md5, tc.a); !bytes.Equal(a, expected) {
+			t.Errorf("%s: expected the same checksum as Checksum %x, got %x", tc.name, expected, a)
+		}
+	}
+	for _, v := range []interface{}{nil, 1, []int{1}, (*int)(nil), struct{}{}} {
+		var ce *ChecksumError
+		if _, err := ChecksumMap(Md5, v); !errors.As(err, &ce) {
+			t.Errorf("%#v: expected a *ChecksumError, got %v", v, err)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+import "github.com/spaolacci/murmur3"
+
+// Murmur3_128 calculates MurmurHash3 (x64, 128-bit) hash value of a byte slice (16-byte output).
+//
+// MurmurHash3 is fast and well-distributed, but is not cryptographically secure.
+func Murmur3_128(input []byte) []byte {
+	hf := murmur3.New128()
+	hf.Write(input)
+	return hf.Sum(nil)
+}
+
+func init() {
+	RegisterHash("murmur3-128", Murmur3_128)
+	builtinDigestSizes[funcPointer(Murmur3_128)] = 16
+}
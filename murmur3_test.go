@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestMurmur3_128(t *testing.T) {
+	// x64 128-bit variant, seed 0: h1 then h2, big-endian
+	testHashVectors(t, "Murmur3_128", Murmur3_128, 16, []hashVector{
+		{"", "00000000000000000000000000000000"},
+		{"hello", "cbd8a7b341bd9b025b1e906a48ae1d19"},
+		{"The quick brown fox jumps over the lazy dog", "e34bbc7bbc071b6c7a433ca9c49a9347"},
+	})
+}
+
+func BenchmarkMurmur3_128(b *testing.B) {
+	benchmarkHashFuncs(b, 64,
+		namedHashFunc{"Murmur3_128", Murmur3_128},
+		namedHashFunc{"Crc64", Crc64},
+		namedHashFunc{"Fnv1a64", Fnv1a64},
+	)
+}
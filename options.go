@@ -0,0 +1,120 @@
+package main
+
+import "golang.org/x/text/unicode/norm"
+
+// config holds the options of a checksum calculation.
+type config struct {
+	fields          fieldOptions
+	orderedStruct   bool
+	stringer        bool
+	sortedMap       bool
+	rawJSON         bool
+	unorderedSlices bool
+	typeTags        bool
+
+	normalizeStrings bool
+	stringForm       norm.Form
+}
+
+// Option customizes a checksum calculation, see ChecksumWith.
+type Option func(*config)
+
+// WithFlattenEmbedded controls whether fields of embedded structs are promoted and checksummed as if they were declared
+// directly on the outer struct (matching Go's field promotion rules), instead of the embedded struct being checksummed
+// as a single field named after its type. Embedded types with a canonical form, such as time.Time, big.Int, types
+// registered via RegisterType and implementations of Checksummer or a marshaler, are not flattened unless unexported.
+// Default: false.
+func WithFlattenEmbedded(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.fields.flatten = enabled
+	}
+}
+
+// WithOrderedStruct controls whether struct fields are serialized sequentially, in declaration order, and hashed once,
+// instead of being checksummed individually and combined regardless of their order. With this option, two structs that
+// differ only by the order their fields are declared in have different checksums. Default: false.
+func WithOrderedStruct(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.orderedStruct = enabled
+	}
+}
+
+// WithStringer controls whether values implementing fmt.Stringer are checksummed via their String() representation
+// instead of being walked, or marshaled (see Checksum). String() is often lossy, hence this is opt-in. A Checksummer
+// implementation still takes precedence, and so do the canonical forms of time.Time, time.Duration, big.Int, big.Rat
+// and json.Number values. A String() method promoted from an embedded field is ignored, e.g. a struct embedding a
+// time.Time is still walked. Default: false.
+func WithStringer(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.stringer = enabled
+	}
+}
+
+// WithSortedMap controls whether map entries are sorted by the checksum of their keys, and their key and value
+// checksums hashed once, instead of each entry being checksummed as a (key, value) tuple and the tuple checksums being
+// combined. Both are independent of the map's iteration order. Default: false.
+func WithSortedMap(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.sortedMap = enabled
+	}
+}
+
+// WithStringNormalization normalizes strings to the specified Unicode normalization form (e.g. norm.NFC) before
+// hashing them, so that canonically equivalent strings, such as "é" as a single code point and "e" followed by a
+// combining accent, have the same checksum. Default: strings are hashed as-is.
+//
+// ChecksumString does not accept options; use ChecksumWith on the string instead.
+func WithStringNormalization(form norm.Form) Option {
+	return func(cfg *config) {
+		cfg.normalizeStrings = true
+		cfg.stringForm = form
+	}
+}
+
+// WithCaseInsensitiveFields controls whether struct field names are lowercased before being mixed into the checksum,
+// so that it is stable across field-casing refactors (e.g. UserId to UserID). Names given by `checksum:"name"` tags
+// are lowercased too. Two fields whose names differ only by case are reported as duplicates by ChecksumWith.
+// Default: false.
+func WithCaseInsensitiveFields(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.fields.foldCase = enabled
+	}
+}
+
+// WithStrictFields controls whether struct fields of func and chan types (e.g. callbacks) make ChecksumWith fail with
+// ErrUnsupportedKind, instead of being skipped as if tagged `checksum:"-"`. Default: false.
+func WithStrictFields(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.fields.strict = enabled
+	}
+}
+
+// WithRawJSON controls whether the content of json.RawMessage values is canonicalized (see ChecksumJSON) before being
+// hashed, so that semantically equal JSON documents that differ by whitespace or key order have the same checksum.
+// Otherwise, like any other byte slice, a json.RawMessage is hashed as an opaque blob. Default: false.
+func WithRawJSON(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.rawJSON = enabled
+	}
+}
+
+// WithTypeTags controls whether the name of each value's concrete type (e.g. "int32" or "main.User") is mixed into its
+// checksum, at every level of nesting, for strict schemas where int32(1) and int64(1), a named type and its underlying
+// type (e.g. Celsius(20) and float64(20) given "type Celsius float64"), or structurally identical structs of different
+// named types, must have different checksums. This couples checksums to Go type (and package) names, so renaming a type
+// changes them. Default: false.
+func WithTypeTags(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.typeTags = enabled
+	}
+}
+
+// WithUnorderedSlices controls whether slices and arrays are checksummed as unordered sets (strictly speaking,
+// multisets): the serialized forms of their elements are sorted before being hashed, like map entries, so that
+// e.g. []int{3, 1, 2} and []int{1, 2, 3} have the same checksum. This applies at every level of nesting, except to
+// byte slices which are still hashed as raw blobs. Default: false.
+func WithUnorderedSlices(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.unorderedSlices = enabled
+	}
+}
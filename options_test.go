@@ -0,0 +1,573 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestWithFlattenEmbedded(t *testing.T) {
+	type Base struct {
+		ID   int
+		Name string
+	}
+	type embedded struct {
+		Base
+		Extra string
+	}
+	type embeddedPointer struct {
+		*Base
+		Extra string
+	}
+	type flat struct {
+		ID    int
+		Name  string
+		Extra string
+	}
+	expected := Checksum(Md5, flat{1, "n", "e"})
+	testCases := []struct {
+		name  string
+		value interface{}
+	}{
+		{"embedded struct", embedded{Base{1, "n"}, "e"}},
+		{"embedded pointer", embeddedPointer{&Base{1, "n"}, "e"}},
+	}
+	for _, tc := range testCases {
+		if actual, _ := ChecksumWith(Md5, tc.value, WithFlattenEmbedded(true)); !bytes.Equal(actual, expected) {
+			t.Errorf("%s: expected %x, got %x", tc.name, expected, actual)
+		}
+		if actual := Checksum(Md5, tc.value); bytes.Equal(actual, expected) {
+			t.Errorf("%s: expected embedded structs not to be flattened by default", tc.name)
+		}
+	}
+
+	// a direct field hides the promoted field with the same name
+	type shadowing struct {
+		Base
+		Name  string
+		Extra string
+	}
+	actual, _ := ChecksumWith(Md5, shadowing{Base{1, "hidden"}, "n", "e"}, WithFlattenEmbedded(true))
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("shadowing: expected %x, got %x", expected, actual)
+	}
+
+	// promoted fields ambiguous at the same depth are excluded, whatever the order of the embedded structs
+	type Other struct {
+		Name string
+	}
+	type ambiguous struct {
+		Base
+		Other
+		Extra string
+	}
+	type ambiguousReversed struct {
+		Other
+		Base
+		Extra string
+	}
+	a, _ := ChecksumWith(Md5, ambiguous{Base{1, "a"}, Other{"b"}, "e"}, WithFlattenEmbedded(true))
+	b, _ := ChecksumWith(Md5, ambiguousReversed{Other{"c"}, Base{1, "d"}, "e"}, WithFlattenEmbedded(true))
+	c := Checksum(Md5, struct {
+		ID    int
+		Extra string
+	}{1, "e"})
+	if !bytes.Equal(a, c) || !bytes.Equal(b, c) {
+		t.Errorf("ambiguous: expected %x, got %x and %x", c, a, b)
+	}
+
+	// embedded types with a canonical form are kept as single fields rather than flattened into their unexported fields
+	type withTime struct {
+		time.Time
+		N int
+	}
+	type withTimePointer struct {
+		*time.Time
+		N int
+	}
+	type withBigInt struct {
+		big.Int
+		N int
+	}
+	type withRat struct {
+		*big.Rat
+		N int
+	}
+	type withFloat struct {
+		big.Float
+		N int
+	}
+	type withRecord struct {
+		OpaqueRecord
+		N int
+	}
+	type UUID struct{ hi, lo uint64 }
+	type withUUID struct {
+		UUID
+		N int
+	}
+	t1, t2 := time.Unix(1, 0), time.Unix(2, 0)
+	canonical := []struct {
+		name string
+		a, b interface{}
+	}{
+		{"time.Time", withTime{t1, 1}, withTime{t2, 1}},
+		{"*time.Time", withTimePointer{&t1, 1}, withTimePointer{&t2, 1}},
+		{"big.Int", withBigInt{*big.NewInt(1), 1}, withBigInt{*big.NewInt(2), 1}},
+		{"*big.Rat", withRat{big.NewRat(1, 2), 1}, withRat{big.NewRat(1, 3), 1}},
+		{"marshaler", withFloat{*big.NewFloat(1), 1}, withFloat{*big.NewFloat(2), 1}},
+		{"checksummer", withRecord{OpaqueRecord{1}, 1}, withRecord{OpaqueRecord{2}, 1}},
+		{"registered", withUUID{UUID{1, 2}, 1}, withUUID{UUID{1, 3}, 1}},
+	}
+	RegisterType(reflect.TypeOf(UUID{}), func(v interface{}) []byte {
+		u := v.(UUID)
+		return []byte(fmt.Sprintf("%016x%016x", u.hi, u.lo))
+	})
+	defer RegisterType(reflect.TypeOf(UUID{}), nil)
+	for _, tc := range canonical {
+		a, err := ChecksumWith(Md5, tc.a, WithFlattenEmbedded(true))
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		if b, _ := ChecksumWith(Md5, tc.b, WithFlattenEmbedded(true)); bytes.Equal(a, b) {
+			t.Errorf("%s: expected the embedded values to be checksummed, both got %x", tc.name, a)
+		}
+		if b := Checksum(Md5, tc.a); !bytes.Equal(a, b) {
+			t.Errorf("%s: expected the same checksum as without flattening, expected %x, got %x", tc.name, b, a)
+		}
+	}
+}
+
+func TestWithOrderedStruct(t *testing.T) {
+	type ab struct {
+		A int
+		B string
+	}
+	type ba struct {
+		B string
+		A int
+	}
+	if a, b := Checksum(Md5, ab{1, "x"}), Checksum(Md5, ba{"x", 1}); !bytes.Equal(a, b) {
+		t.Errorf("expected field order not to matter by default, got %x and %x", a, b)
+	}
+	a, _ := ChecksumWith(Md5, ab{1, "x"}, WithOrderedStruct(true))
+	b, _ := ChecksumWith(Md5, ba{"x", 1}, WithOrderedStruct(true))
+	if a == nil || bytes.Equal(a, b) {
+		t.Errorf("expected field order to matter under the option, got %x and %x", a, b)
+	}
+	again, _ := ChecksumWith(Md5, ab{1, "x"}, WithOrderedStruct(true))
+	other, _ := ChecksumWith(Md5, ab{2, "x"}, WithOrderedStruct(true))
+	if !bytes.Equal(a, again) || bytes.Equal(a, other) {
+		t.Errorf("expected ordered checksums to be stable and value-dependent")
+	}
+	// field boundaries stay unambiguous
+	type pair struct{ A, B string }
+	c, _ := ChecksumWith(Md5, pair{"ab", "c"}, WithOrderedStruct(true))
+	d, _ := ChecksumWith(Md5, pair{"a", "bc"}, WithOrderedStruct(true))
+	if bytes.Equal(c, d) {
+		t.Errorf("expected shifted field contents to have different checksums, both got %x", c)
+	}
+}
+
+func BenchmarkWithOrderedStruct(b *testing.B) {
+	type record struct {
+		ID, Age       int
+		Name, Email   string
+		Active, Admin bool
+		Score, Ratio  float64
+	}
+	v := record{1, 42, "name", "name@example.com", true, false, 1.5, 0.25}
+	b.Run("Default", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ChecksumWith(Md5, v)
+		}
+	})
+	b.Run("Ordered", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ChecksumWith(Md5, v, WithOrderedStruct(true))
+		}
+	})
+}
+
+// color is an enum with a canonical String representation.
+type color int
+
+func (c color) String() string {
+	return [...]string{"red", "green", "blue"}[c]
+}
+
+// labeledColor is a struct whose String representation ignores its label.
+type labeledColor struct {
+	Color color
+	Label string
+}
+
+func (c labeledColor) String() string {
+	return c.Color.String()
+}
+
+func TestWithStringer(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	instant := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	type event struct {
+		time.Time
+		Name string
+	}
+	testCases := []struct {
+		name        string
+		a, b        interface{}
+		sameWith    bool
+		sameWithout bool
+	}{
+		{"struct", labeledColor{1, "a"}, labeledColor{1, "b"}, true, false},
+		{"enum and its string", color(1), "green", false, false},
+		{"net.IP forms", net.ParseIP("10.0.0.1"), net.IP{10, 0, 0, 1}, true, true},
+		{"net.IP different", net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2"), false, false},
+		{"time.Time locations", instant, instant.In(newYork), true, true},
+		{"*time.Time locations", &instant, instant.In(newYork), true, true},
+		{"json.Number forms", json.Number("1.0"), json.Number("1"), true, true},
+		{"big.Int", big.NewInt(10), *big.NewInt(10), true, true},
+		// a String() method promoted from an embedded field is ignored: the struct is walked
+		{"embedded stringer", event{instant, "a"}, event{instant, "b"}, false, false},
+		{"embedded stringer locations", event{instant, "a"}, event{instant.In(newYork), "a"}, true, true},
+	}
+	for _, tc := range testCases {
+		a, _ := ChecksumWith(Md5, tc.a, WithStringer(true))
+		b, _ := ChecksumWith(Md5, tc.b, WithStringer(true))
+		if a == nil || bytes.Equal(a, b) != tc.sameWith {
+			t.Errorf("%s: expected same checksums to be %v with the option, got %x and %x", tc.name, tc.sameWith, a, b)
+		}
+		if a, b := Checksum(Md5, tc.a), Checksum(Md5, tc.b); a == nil || bytes.Equal(a, b) != tc.sameWithout {
+			t.Errorf("%s: expected same checksums to be %v without the option, got %x and %x", tc.name, tc.sameWithout, a, b)
+		}
+	}
+	expected := Md5(append([]byte{tagStringer}, "green"...))
+	if actual, _ := ChecksumWith(Md5, color(1), WithStringer(true)); !bytes.Equal(actual, expected) {
+		t.Errorf("expected String() to be hashed, got %x instead of %x", actual, expected)
+	}
+	if actual := Checksum(Md5, color(1)); !bytes.Equal(actual, Checksum(Md5, 1)) {
+		t.Errorf("expected String() to be ignored without the option")
+	}
+}
+
+func TestWithSortedMap(t *testing.T) {
+	a, b := xorCollision(t)
+	if !bytes.Equal(xorCombine(Md5, a), xorCombine(Md5, b)) {
+		t.Fatalf("expected %v and %v to collide under XOR", a, b)
+	}
+	ca, _ := ChecksumWith(Md5, a, WithSortedMap(true))
+	cb, _ := ChecksumWith(Md5, b, WithSortedMap(true))
+	if ca == nil || bytes.Equal(ca, cb) {
+		t.Errorf("expected maps colliding under XOR to have different checksums, got %x and %x", ca, cb)
+	}
+
+	m1 := map[string]int{}
+	m2 := map[string]int{}
+	for i := 0; i < 100; i++ {
+		m1[fmt.Sprint(i)] = i
+		m2[fmt.Sprint(99-i)] = 99 - i
+	}
+	c1, _ := ChecksumWith(Md5, m1, WithSortedMap(true))
+	c2, _ := ChecksumWith(Md5, m2, WithSortedMap(true))
+	if c1 == nil || !bytes.Equal(c1, c2) {
+		t.Errorf("expected equal maps to have the same checksum, got %x and %x", c1, c2)
+	}
+	m2["0"] = 1
+	if c3, _ := ChecksumWith(Md5, m2, WithSortedMap(true)); bytes.Equal(c1, c3) {
+		t.Errorf("expected different maps to have different checksums, both got %x", c1)
+	}
+}
+
+func TestWithStringNormalization(t *testing.T) {
+	nfc, nfd := "café", "café"
+	if nfc == nfd || norm.NFC.String(nfd) != nfc {
+		t.Fatalf("expected distinct but canonically equivalent strings")
+	}
+	type menu struct{ Items []string }
+	testCases := []struct {
+		name string
+		a, b interface{}
+	}{
+		{"string", nfc, nfd},
+		{"nested", menu{[]string{nfc}}, menu{[]string{nfd}}},
+		{"map key", map[string]int{nfc: 1}, map[string]int{nfd: 1}},
+	}
+	for _, tc := range testCases {
+		a, _ := ChecksumWith(Md5, tc.a, WithStringNormalization(norm.NFC))
+		b, _ := ChecksumWith(Md5, tc.b, WithStringNormalization(norm.NFC))
+		if a == nil || !bytes.Equal(a, b) {
+			t.Errorf("%s: expected equal checksums when normalizing, got %x and %x", tc.name, a, b)
+		}
+		if a, b := Checksum(Md5, tc.a), Checksum(Md5, tc.b); bytes.Equal(a, b) {
+			t.Errorf("%s: expected different checksums without normalization, both got %x", tc.name, a)
+		}
+	}
+	if a, _ := ChecksumWith(Md5, nfc, WithStringNormalization(norm.NFC)); !bytes.Equal(a, ChecksumString(Md5, nfc)) {
+		t.Errorf("expected normalized strings to hash like ChecksumString")
+	}
+}
+
+func TestWithCaseInsensitiveFields(t *testing.T) {
+	type before struct{ UserId int }
+	type after struct{ UserID int }
+	a, _ := ChecksumWith(Md5, before{1}, WithCaseInsensitiveFields(true))
+	b, _ := ChecksumWith(Md5, after{1}, WithCaseInsensitiveFields(true))
+	if a == nil || !bytes.Equal(a, b) {
+		t.Errorf("expected equal checksums under the option, got %x and %x", a, b)
+	}
+	if a, b := Checksum(Md5, before{1}), Checksum(Md5, after{1}); bytes.Equal(a, b) {
+		t.Errorf("expected different checksums without the option, both got %x", a)
+	}
+	type tagged struct {
+		ID int `checksum:"USERID"`
+	}
+	if c, _ := ChecksumWith(Md5, tagged{1}, WithCaseInsensitiveFields(true)); !bytes.Equal(a, c) {
+		t.Errorf("expected tag names to be lowercased too, got %x and %x", a, c)
+	}
+	type clashing struct {
+		Name, NAME string
+	}
+	if _, err := ChecksumWith(Md5, clashing{}, WithCaseInsensitiveFields(true)); !errors.Is(err, ErrDuplicateFieldName) {
+		t.Errorf("expected ErrDuplicateFieldName, got %v", err)
+	}
+	if _, err := ChecksumE(Md5, clashing{}); err != nil {
+		t.Errorf("expected no error without the option, got %v", err)
+	}
+}
+
+func TestChecksumWithCombinedOptions(t *testing.T) {
+	type Base struct{ UserId int }
+	type embedded struct {
+		Base
+		Name string
+	}
+	type flat struct {
+		UserID int
+		Name   string
+	}
+	opts := []Option{WithFlattenEmbedded(true), WithCaseInsensitiveFields(true)}
+	a, _ := ChecksumWith(Md5, embedded{Base{1}, "n"}, opts...)
+	b, _ := ChecksumWith(Md5, flat{1, "n"}, opts...)
+	if a == nil || !bytes.Equal(a, b) {
+		t.Errorf("expected promoted fields to be case-folded, got %x and %x", a, b)
+	}
+	reversed, _ := ChecksumWith(Md5, embedded{Base{1}, "n"}, opts[1], opts[0])
+	if !bytes.Equal(a, reversed) {
+		t.Errorf("expected independent options to commute, got %x and %x", a, reversed)
+	}
+	for _, opt := range opts {
+		if c, _ := ChecksumWith(Md5, embedded{Base{1}, "n"}, opt); bytes.Equal(a, c) {
+			t.Errorf("expected each option alone to give a different checksum, both got %x", a)
+		}
+	}
+	if c, _ := ChecksumWith(Md5, flat{1, "n"}, WithOrderedStruct(true), WithOrderedStruct(false)); !bytes.Equal(c, Checksum(Md5, flat{1, "n"})) {
+		t.Errorf("expected later options to override earlier ones")
+	}
+	if c, _ := ChecksumWith(Md5, flat{1, "n"}); !bytes.Equal(c, Checksum(Md5, flat{1, "n"})) {
+		t.Errorf("expected ChecksumWith without options to equal Checksum")
+	}
+}
+
+func TestWithRawJSON(t *testing.T) {
+	type event struct {
+		Kind    string
+		Payload json.RawMessage
+	}
+	compact := event{"created", json.RawMessage(`{"a":1,"b":[true,null]}`)}
+	spaced := event{"created", json.RawMessage("{ \"b\": [true, null],\n  \"a\": 1 }")}
+
+	if a, b := Checksum(Md5, compact.Payload), Checksum(Md5, []byte(compact.Payload)); !bytes.Equal(a, b) {
+		t.Errorf("expected json.RawMessage to be hashed as an opaque blob by default, got %x and %x", a, b)
+	}
+	if a, b := Checksum(Md5, compact), Checksum(Md5, spaced); bytes.Equal(a, b) {
+		t.Errorf("expected different JSON texts to have different checksums by default, both got %x", a)
+	}
+	a, errA := ChecksumWith(Md5, compact, WithRawJSON(true))
+	b, errB := ChecksumWith(Md5, spaced, WithRawJSON(true))
+	if errA != nil || errB != nil || !bytes.Equal(a, b) {
+		t.Errorf("expected semantically equal JSON to have the same checksum, got %x and %x (%v, %v)", a, b, errA, errB)
+	}
+	other := event{"created", json.RawMessage(`{"a":2,"b":[true,null]}`)}
+	if c, _ := ChecksumWith(Md5, other, WithRawJSON(true)); bytes.Equal(a, c) {
+		t.Errorf("expected different JSON documents to have different checksums, both got %x", a)
+	}
+	if _, err := ChecksumWith(Md5, event{"created", json.RawMessage(`{"a":`)}, WithRawJSON(true)); err == nil {
+		t.Errorf("expected invalid JSON to be reported")
+	}
+	// the canonical form is tagged, like any other: it differs from the hash of the JSON text or of a string
+	raw, _ := ChecksumWith(Md5, json.RawMessage("1"), WithRawJSON(true))
+	canonical, _ := canonicalJSON(json.RawMessage("1"))
+	for _, other := range [][]byte{Md5([]byte("1")), Md5(canonical), Checksum(Md5, "1"), Checksum(Md5, 1)} {
+		if bytes.Equal(raw, other) {
+			t.Errorf("expected the canonical JSON to be tagged, got %x", raw)
+		}
+	}
+	if expected := Md5(append([]byte{tagJSON}, canonical...)); !bytes.Equal(raw, expected) {
+		t.Errorf("expected %x, got %x", expected, raw)
+	}
+}
+
+func TestWithSortedMapKeyCollisions(t *testing.T) {
+	// a weak hash under which all keys collide: only longer inputs, such as the values, are really hashed
+	weak := func(input []byte) []byte {
+		if len(input) < 16 {
+			return []byte{0}
+		}
+		return Md5(input)
+	}
+	m := map[string]string{}
+	for i := 0; i < 20; i++ {
+		m[fmt.Sprint(i)] = fmt.Sprintf("value number %03d", i)
+	}
+	expected, err := ChecksumWith(weak, m, WithSortedMap(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for run := 0; run < 100; run++ {
+		if actual, _ := ChecksumWith(weak, m, WithSortedMap(true)); !bytes.Equal(actual, expected) {
+			t.Fatalf("run %d: expected %x, got %x", run, expected, actual)
+		}
+	}
+	// entries with colliding key checksums are ordered by the keys' canonical bytes
+	swapped := map[string]string{}
+	for k, v := range m {
+		swapped[k] = v
+	}
+	swapped["0"], swapped["1"] = m["1"], m["0"]
+	if actual, _ := ChecksumWith(weak, swapped, WithSortedMap(true)); bytes.Equal(actual, expected) {
+		t.Errorf("expected swapped values to give a different checksum, both got %x", actual)
+	}
+}
+
+func TestWithTypeTags(t *testing.T) {
+	type Celsius float64
+	type Count int
+	type Label string
+	type user struct{ Name string }
+	type admin struct{ Name string }
+	testCases := []struct {
+		name string
+		a, b interface{}
+	}{
+		{"int widths", int32(1), int64(1)},
+		{"named float", Celsius(20), float64(20)},
+		{"named int", Count(3), 3},
+		{"named string", Label("x"), "x"},
+		{"named field", struct{ T interface{} }{Celsius(20)}, struct{ T interface{} }{float64(20)}},
+		{"named structs", user{"n"}, admin{"n"}},
+		{"nested", []interface{}{int8(1)}, []interface{}{int16(1)}},
+		{"map values", map[string]interface{}{"k": user{"n"}}, map[string]interface{}{"k": admin{"n"}}},
+	}
+	for _, tc := range testCases {
+		a, _ := ChecksumWith(Md5, tc.a, WithTypeTags(true))
+		b, _ := ChecksumWith(Md5, tc.b, WithTypeTags(true))
+		if a == nil || bytes.Equal(a, b) {
+			t.Errorf("%s: expected different checksums under the option, both got %x", tc.name, a)
+		}
+		if a, b := Checksum(Md5, tc.a), Checksum(Md5, tc.b); !bytes.Equal(a, b) {
+			t.Errorf("%s: expected equal checksums without the option, got %x and %x", tc.name, a, b)
+		}
+	}
+	a, _ := ChecksumWith(Md5, []int{1}, WithTypeTags(true))
+	b, _ := ChecksumWith(Md5, 1, WithTypeTags(true))
+	if bytes.Equal(a, b) {
+		t.Errorf("expected a slice and its sole element to differ, both got %x", a)
+	}
+	c, _ := ChecksumWith(Md5, user{"n"}, WithTypeTags(true))
+	d, _ := ChecksumWith(Md5, &user{"n"}, WithTypeTags(true))
+	if !bytes.Equal(c, d) {
+		t.Errorf("expected pointers to have the same checksum as the values they point to, got %x and %x", c, d)
+	}
+}
+
+func TestWithStrictFields(t *testing.T) {
+	type widget struct {
+		Name     string
+		OnChange func()
+		Events   chan int
+	}
+	type plain struct {
+		Name string
+	}
+	expected := Checksum(Md5, plain{"w"})
+	for _, v := range []widget{{Name: "w"}, {Name: "w", OnChange: func() {}, Events: make(chan int)}} {
+		actual, err := ChecksumE(Md5, v)
+		if err != nil || !bytes.Equal(actual, expected) {
+			t.Errorf("expected func and chan fields to be skipped: expected %x, got %x (%v)", expected, actual, err)
+		}
+	}
+	if actual := Checksum(Md5, widget{Name: "x"}); bytes.Equal(actual, expected) {
+		t.Errorf("expected the other fields to be checksummed")
+	}
+	_, err := ChecksumWith(Md5, widget{Name: "w", OnChange: func() {}}, WithStrictFields(true))
+	var ce *ChecksumError
+	if !errors.As(err, &ce) || !errors.Is(err, ErrUnsupportedKind) || ce.Path != ".OnChange" {
+		t.Errorf("expected an ErrUnsupportedKind error at .OnChange under the option, got %v", err)
+	}
+	// func values themselves remain unsupported
+	if _, err := ChecksumE(Md5, func() {}); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("expected ErrUnsupportedKind for a func value, got %v", err)
+	}
+}
+
+func TestWithUnorderedSlices(t *testing.T) {
+	type group struct {
+		Name    string
+		Members []string
+	}
+	testCases := []struct {
+		name string
+		a, b interface{}
+	}{
+		{"ints", []int{3, 1, 2}, []int{1, 2, 3}},
+		{"arrays", [3]string{"c", "a", "b"}, [3]string{"a", "b", "c"}},
+		{"nested", [][]int{{2, 1}, {4, 3}}, [][]int{{3, 4}, {1, 2}}},
+		{"in structs", []group{{"x", []string{"b", "a"}}, {"y", nil}}, []group{{"y", nil}, {"x", []string{"a", "b"}}}},
+		{"in maps", map[string][]int{"k": {2, 1}}, map[string][]int{"k": {1, 2}}},
+	}
+	for _, tc := range testCases {
+		a, _ := ChecksumWith(Md5, tc.a, WithUnorderedSlices(true))
+		b, _ := ChecksumWith(Md5, tc.b, WithUnorderedSlices(true))
+		if a == nil || !bytes.Equal(a, b) {
+			t.Errorf("%s: expected equal checksums under the option, got %x and %x", tc.name, a, b)
+		}
+		if a, b := Checksum(Md5, tc.a), Checksum(Md5, tc.b); bytes.Equal(a, b) {
+			t.Errorf("%s: expected different checksums without the option, both got %x", tc.name, a)
+		}
+	}
+	// multiset semantics: duplicates count, and content still matters
+	different := []struct {
+		name string
+		a, b interface{}
+	}{
+		{"duplicates", []int{1, 1, 2}, []int{1, 2, 2}},
+		{"length", []int{1, 2}, []int{1, 2, 2}},
+		{"content", []int{1, 2, 3}, []int{1, 2, 4}},
+		{"nesting", [][]int{{1, 2}, {3}}, [][]int{{1}, {2, 3}}},
+	}
+	for _, tc := range different {
+		a, _ := ChecksumWith(Md5, tc.a, WithUnorderedSlices(true))
+		if b, _ := ChecksumWith(Md5, tc.b, WithUnorderedSlices(true)); bytes.Equal(a, b) {
+			t.Errorf("%s: expected different checksums under the option, both got %x", tc.name, a)
+		}
+	}
+	// byte slices are still hashed as raw blobs
+	a, _ := ChecksumWith(Md5, []byte("ab"), WithUnorderedSlices(true))
+	if b, _ := ChecksumWith(Md5, []byte("ba"), WithUnorderedSlices(true)); bytes.Equal(a, b) {
+		t.Errorf("expected byte slices to remain ordered, both got %x", a)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/crc32"
+)
+
+// ChecksumHex is similar to Checksum, but returns the checksum as a lowercase hex string.
+func ChecksumHex(hf HashFunc, v interface{}) string {
+	return hex.EncodeToString(Checksum(hf, v))
+}
+
+// ChecksumBoolHex is similar to ChecksumBool, but returns the checksum as a lowercase hex string.
+func ChecksumBoolHex(hf HashFunc, input bool) string {
+	return hex.EncodeToString(ChecksumBool(hf, input))
+}
+
+// ChecksumIntHex is similar to ChecksumInt, but returns the checksum as a lowercase hex string.
+func ChecksumIntHex(hf HashFunc, input int64) string {
+	return hex.EncodeToString(ChecksumInt(hf, input))
+}
+
+// ChecksumUintHex is similar to ChecksumUint, but returns the checksum as a lowercase hex string.
+func ChecksumUintHex(hf HashFunc, input uint64) string {
+	return hex.EncodeToString(ChecksumUint(hf, input))
+}
+
+// ChecksumFloatHex is similar to ChecksumFloat, but returns the checksum as a lowercase hex string.
+func ChecksumFloatHex(hf HashFunc, input float64) string {
+	return hex.EncodeToString(ChecksumFloat(hf, input))
+}
+
+// ChecksumStringHex is similar to ChecksumString, but returns the checksum as a lowercase hex string.
+func ChecksumStringHex(hf HashFunc, input string) string {
+	return hex.EncodeToString(ChecksumString(hf, input))
+}
+
+// ChecksumBase64 is similar to Checksum, but returns the checksum encoded with standard base64 encoding.
+func ChecksumBase64(hf HashFunc, v interface{}) string {
+	return base64.StdEncoding.EncodeToString(Checksum(hf, v))
+}
+
+// ChecksumBase64URL is similar to Checksum, but returns the checksum encoded with URL-safe base64 encoding, suitable
+// for URLs and file names.
+func ChecksumBase64URL(hf HashFunc, v interface{}) string {
+	return base64.URLEncoding.EncodeToString(Checksum(hf, v))
+}
+
+// Checksum64 is similar to Checksum, but returns the first 8 bytes of the checksum as a big-endian uint64, e.g. for
+// sharding or as a hash-map key. Checksums shorter than 8 bytes (e.g. CRC32) are zero-extended, i.e. interpreted as
+// a big-endian integer.
+func Checksum64(hf HashFunc, v interface{}) uint64 {
+	digest := Checksum(hf, v)
+	if len(digest) >= 8 {
+		return binary.BigEndian.Uint64(digest)
+	}
+	var buf [8]byte
+	copy(buf[8-len(digest):], digest)
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+// AppendChecksum is similar to Checksum, but appends the checksum to dst and returns the extended slice, following the
+// convention of the standard library's AppendX functions. For the built-in cryptographic hash functions, the digest is
+// written into dst directly: this does not allocate if dst has enough capacity. If v can not be checksummed, dst is
+// returned unchanged.
+func AppendChecksum(dst []byte, hf HashFunc, v interface{}) []byte {
+	appendHash, ok := builtinAppenders[funcPointer(hf)]
+	if !ok {
+		return append(dst, Checksum(hf, v)...)
+	}
+	data, isDigest, err := newWalker(context.Background(), hf, nil).encodeTyped(v)
+	if err != nil {
+		return dst
+	}
+	if isDigest {
+		return append(dst, data...)
+	}
+	return appendHash(dst, data)
+}
+
+// builtinAppenders append the digest of input to dst for built-in hash functions, keyed by function pointer (see
+// builtinDigestSizes), without allocating a digest slice.
+var builtinAppenders = map[uintptr]func(dst, input []byte) []byte{
+	funcPointer(Md5): func(dst, input []byte) []byte {
+		digest := md5.Sum(input)
+		return append(dst, digest[:]...)
+	},
+	funcPointer(Sha1): func(dst, input []byte) []byte {
+		digest := sha1.Sum(input)
+		return append(dst, digest[:]...)
+	},
+	funcPointer(Sha256): func(dst, input []byte) []byte {
+		digest := sha256.Sum256(input)
+		return append(dst, digest[:]...)
+	},
+	funcPointer(Sha512): func(dst, input []byte) []byte {
+		digest := sha512.Sum512(input)
+		return append(dst, digest[:]...)
+	},
+	funcPointer(Sha512_256): func(dst, input []byte) []byte {
+		digest := sha512.Sum512_256(input)
+		return append(dst, digest[:]...)
+	},
+	funcPointer(Crc32): func(dst, input []byte) []byte {
+		return binary.BigEndian.AppendUint32(dst, crc32.ChecksumIEEE(input))
+	},
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+func TestChecksumHex(t *testing.T) {
+	testCases := []struct {
+		name   string
+		hex    string
+		digest []byte
+	}{
+		{"Checksum", ChecksumHex(Sha256, map[string]int{"a": 1}), Checksum(Sha256, map[string]int{"a": 1})},
+		{"ChecksumBool", ChecksumBoolHex(Md5, true), ChecksumBool(Md5, true)},
+		{"ChecksumInt", ChecksumIntHex(Md5, -1), ChecksumInt(Md5, -1)},
+		{"ChecksumUint", ChecksumUintHex(Crc32, 1), ChecksumUint(Crc32, 1)},
+		{"ChecksumFloat", ChecksumFloatHex(Sha1, 1.5), ChecksumFloat(Sha1, 1.5)},
+		{"ChecksumString", ChecksumStringHex(Md5, "abc"), ChecksumString(Md5, "abc")},
+	}
+	for _, tc := range testCases {
+		if len(tc.hex) != 2*len(tc.digest) {
+			t.Errorf("%s: expected %d hex digits, got %d", tc.name, 2*len(tc.digest), len(tc.hex))
+		}
+		decoded, err := hex.DecodeString(tc.hex)
+		if err != nil || !bytes.Equal(decoded, tc.digest) {
+			t.Errorf("%s: expected %q to decode to %x, got %x (%v)", tc.name, tc.hex, tc.digest, decoded, err)
+		}
+		if tc.hex != hex.EncodeToString(tc.digest) {
+			t.Errorf("%s: expected lowercase hex, got %q", tc.name, tc.hex)
+		}
+	}
+}
+
+func TestChecksumBase64(t *testing.T) {
+	encodings := []struct {
+		name     string
+		encode   func(HashFunc, interface{}) string
+		encoding *base64.Encoding
+	}{
+		{"StdEncoding", ChecksumBase64, base64.StdEncoding},
+		{"URLEncoding", ChecksumBase64URL, base64.URLEncoding},
+	}
+	for _, e := range encodings {
+		for _, v := range []interface{}{"", []int{}, map[string]int{"a": 1}} {
+			expected := Checksum(Sha256, v)
+			decoded, err := e.encoding.DecodeString(e.encode(Sha256, v))
+			if err != nil || !bytes.Equal(decoded, expected) {
+				t.Errorf("%s(%#v): expected %x, got %x (%v)", e.name, v, expected, decoded, err)
+			}
+		}
+		if actual := e.encode(Sha256, make(chan int)); actual != "" {
+			t.Errorf("%s: expected an empty string for a value that can not be checksummed, got %q", e.name, actual)
+		}
+	}
+}
+
+func TestChecksum64(t *testing.T) {
+	for _, v := range []interface{}{"x", 42, map[string]int{"a": 1}} {
+		if actual, expected := Checksum64(Crc64, v), binary.BigEndian.Uint64(Checksum(Crc64, v)); actual != expected {
+			t.Errorf("Checksum64(Crc64, %v): expected %d, got %d", v, expected, actual)
+		}
+		expected := binary.BigEndian.Uint64(Checksum(Sha256, v)[:8])
+		if actual := Checksum64(Sha256, v); actual != expected {
+			t.Errorf("Checksum64(Sha256, %v): expected %d, got %d", v, expected, actual)
+		}
+		// shorter digests are zero-extended
+		if actual, expected := Checksum64(Crc32, v), uint64(binary.BigEndian.Uint32(Checksum(Crc32, v))); actual != expected {
+			t.Errorf("Checksum64(Crc32, %v): expected %d, got %d", v, expected, actual)
+		}
+	}
+}
+
+func TestAppendChecksum(t *testing.T) {
+	prefix := []byte("etag:")
+	for _, v := range []interface{}{int64(1), "x", []int{1, 2}} {
+		actual := AppendChecksum(append([]byte{}, prefix...), Sha256, v)
+		if expected := append(append([]byte{}, prefix...), Checksum(Sha256, v)...); !bytes.Equal(actual, expected) {
+			t.Errorf("%v: expected %x, got %x", v, expected, actual)
+		}
+	}
+	if actual := AppendChecksum(nil, Md5, "x"); !bytes.Equal(actual, Checksum(Md5, "x")) {
+		t.Errorf("expected appending to nil to equal Checksum, got %x", actual)
+	}
+	// built-in hash functions write into dst, others fall back to Checksum
+	values := []interface{}{nil, int64(1), "x", []byte("abc"), map[string]int{"a": 1}, cachedRecord{ID: 1}}
+	for _, hf := range []namedHashFunc{{"Md5", Md5}, {"Sha1", Sha1}, {"Sha256", Sha256}, {"Sha512", Sha512},
+		{"Sha512_256", Sha512_256}, {"Crc32", Crc32}, {"Fnv1a64", Fnv1a64}, {"HmacSha256", HmacSha256([]byte("k"))}} {
+		for _, v := range values {
+			expected := append(append([]byte{}, prefix...), Checksum(hf.hf, v)...)
+			if actual := AppendChecksum(append([]byte{}, prefix...), hf.hf, v); !bytes.Equal(actual, expected) {
+				t.Errorf("%s, %v: expected %x, got %x", hf.name, v, expected, actual)
+			}
+		}
+	}
+	dst := make([]byte, 0, 64)
+	appendAllocs := testing.AllocsPerRun(10, func() { dst = AppendChecksum(dst[:0], Sha256, "x") })
+	checksumAllocs := testing.AllocsPerRun(10, func() { dst = append(dst[:0], Checksum(Sha256, "x")...) })
+	if appendAllocs >= checksumAllocs {
+		t.Errorf("expected fewer allocations than Checksum (%v), got %v", checksumAllocs, appendAllocs)
+	}
+	if actual := AppendChecksum(prefix, Md5, make(chan int)); !bytes.Equal(actual, prefix) {
+		t.Errorf("expected dst to be returned unchanged on error, got %q", actual)
+	}
+}
+
+// BenchmarkAppendChecksum builds prefixed keys, reusing a preallocated buffer or allocating a new key every time.
+func BenchmarkAppendChecksum(b *testing.B) {
+	prefix := []byte("etag:")
+	b.Run("AppendChecksum", func(b *testing.B) {
+		b.ReportAllocs()
+		dst := make([]byte, 0, 64)
+		for i := 0; i < b.N; i++ {
+			dst = AppendChecksum(append(dst[:0], prefix...), Sha256, int64(i))
+		}
+	})
+	b.Run("Checksum", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			key := make([]byte, 0, len(prefix)+32)
+			_ = append(append(key, prefix...), Checksum(Sha256, int64(i))...)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// parallelMinLen is the minimum number of elements for ChecksumParallel to checksum a slice concurrently; smaller
+// slices are checksummed sequentially as the overhead of goroutines would dominate.
+const parallelMinLen = 1024
+
+// ChecksumParallel is similar to Checksum (or ChecksumWith if options are specified), but if the value is a large
+// slice or array, its elements are checksummed concurrently by up to GOMAXPROCS workers. The result is exactly the
+// same as Checksum's.
+func ChecksumParallel(hf HashFunc, v interface{}, opts ...Option) []byte {
+	w := newWalker(context.Background(), hf, opts)
+	if kind := reflect.ValueOf(v).Kind(); kind == reflect.Slice || kind == reflect.Array {
+		w.parallel = true
+	}
+	result, _ := w.checksum(v)
+	return result
+}
+
+// parallelEncodings encodes all elements of a slice/array concurrently (see encodeElement), each worker with its own
+// walker. If several elements fail, the error of the first one (by index) is returned.
+func (w *walker) parallelEncodings(rv reflect.Value) ([][]byte, error) {
+	n := rv.Len()
+	entries := make([][]byte, n)
+	errs := make([]error, n)
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			ww := &walker{ctx: w.ctx, hf: w.hf, cfg: w.cfg, visiting: make(map[visitKey]bool, len(w.visiting))}
+			for key := range w.visiting {
+				// so that elements referencing the slice itself are still detected as cycles
+				ww.visiting[key] = true
+			}
+			for i := worker; i < n; i += workers {
+				entries[i], errs[i] = ww.encodeElement(nil, rv, i)
+			}
+		}(worker)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+// parallelRecords builds a slice of n distinct nested structs.
+func parallelRecords(n int) []nestedRecord {
+	records := make([]nestedRecord, n)
+	for i := range records {
+		records[i] = testNestedRecord
+		records[i].ID = i
+	}
+	return records
+}
+
+func TestChecksumParallel(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value interface{}
+		opts  []Option
+	}{
+		{"large slice", parallelRecords(2 * parallelMinLen), nil},
+		{"small slice", parallelRecords(10), nil},
+		{"large array", [parallelMinLen]int{1, 2, 3}, nil},
+		{"empty slice", []int{}, nil},
+		{"not a slice", testNestedRecord, nil},
+		{"with options", parallelRecords(parallelMinLen), []Option{WithUnorderedSlices(true), WithTypeTags(true)}},
+	}
+	for _, tc := range testCases {
+		expected, _ := ChecksumWith(Sha256, tc.value, tc.opts...)
+		if actual := ChecksumParallel(Sha256, tc.value, tc.opts...); actual == nil || !bytes.Equal(actual, expected) {
+			t.Errorf("%s: expected %x, got %x", tc.name, expected, actual)
+		}
+	}
+
+	cyclic := make([]interface{}, parallelMinLen)
+	cyclic[7] = cyclic
+	if actual, expected := ChecksumParallel(Md5, cyclic), Checksum(Md5, cyclic); actual == nil || !bytes.Equal(actual, expected) {
+		t.Errorf("cyclic slice: expected %x, got %x", expected, actual)
+	}
+
+	failing := make([]interface{}, parallelMinLen)
+	failing[3], failing[5] = make(chan int), func() {}
+	w := newWalker(context.Background(), Md5, nil)
+	w.parallel = true
+	if _, err := w.checksum(failing); !errors.Is(err, ErrUnsupportedKind) || err.Error() != "checksum: [3]: unsupported kind chan" {
+		t.Errorf("expected the error of the first failing element, got %v", err)
+	}
+}
+
+func BenchmarkChecksumParallel(b *testing.B) {
+	records := parallelRecords(10000)
+	b.Run("Sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Checksum(Sha256, records)
+		}
+	})
+	b.Run("Parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ChecksumParallel(Sha256, records)
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"hash"
+	"sync"
+)
+
+// NewPooledFunc builds a HashFunc that reuses hasher instances created by h (e.g. sha256.New) via a sync.Pool,
+// instead of allocating a new one on every call. The returned function is safe for concurrent use.
+//
+// Pooling pays off for hashers that are expensive to create, such as HMAC ones (see NewHmacFunc). Plain hashers such
+// as sha256.New are usually cheap enough, or even kept off the heap by the compiler, to gain nothing from it.
+func NewPooledFunc(h func() hash.Hash) HashFunc {
+	pool := &sync.Pool{New: func() interface{} { return h() }}
+	return func(input []byte) []byte {
+		hf := pool.Get().(hash.Hash)
+		hf.Reset()
+		hf.Write(input)
+		result := hf.Sum(nil)
+		pool.Put(hf)
+		return result
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"sync"
+	"testing"
+)
+
+// nestedRecord is a nested struct, walked field by field.
+type nestedRecord struct {
+	ID       int
+	Name     string
+	Tags     []string
+	Scores   map[string]float64
+	Children []nestedRecord
+}
+
+var testNestedRecord = nestedRecord{
+	ID:     1,
+	Name:   "root",
+	Tags:   []string{"a", "b", "c"},
+	Scores: map[string]float64{"x": 1.5, "y": 2.5},
+	Children: []nestedRecord{
+		{ID: 2, Name: "child", Tags: []string{"d"}},
+		{ID: 3, Name: "other child", Scores: map[string]float64{"z": 3}},
+	},
+}
+
+func TestPooledFuncs(t *testing.T) {
+	testCases := []struct {
+		name          string
+		pooled, plain HashFunc
+	}{
+		{"Md5", NewPooledFunc(md5.New), Md5},
+		{"Sha1", NewPooledFunc(sha1.New), Sha1},
+		{"Sha256", NewPooledFunc(sha256.New), Sha256},
+		{"Sha512", NewPooledFunc(sha512.New), Sha512},
+		{"HmacSha256", NewPooledFunc(func() hash.Hash { return hmac.New(sha256.New, []byte("key")) }), HmacSha256([]byte("key"))},
+	}
+	for _, tc := range testCases {
+		expected := Checksum(tc.plain, testNestedRecord)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					if actual := Checksum(tc.pooled, testNestedRecord); !bytes.Equal(actual, expected) {
+						t.Errorf("%s: expected %x, got %x", tc.name, expected, actual)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// BenchmarkPooledFunc compares allocations per Checksum of a nested struct. Recent compilers may keep plain hashers
+// such as sha256.New on the stack; HMAC hashers always escape to the heap.
+func BenchmarkPooledFunc(b *testing.B) {
+	key := []byte("key")
+	hmacSha256 := func() hash.Hash { return hmac.New(sha256.New, key) }
+	hfs := []namedHashFunc{
+		{"Sha256", Sha256},
+		{"HmacSha256", NewHmacFunc(sha256.New, key)},
+		{"HmacSha256Pooled", NewPooledFunc(hmacSha256)},
+	}
+	for _, hf := range hfs {
+		b.Run(hf.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Checksum(hf.hf, testNestedRecord)
+			}
+		})
+	}
+}
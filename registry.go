@@ -0,0 +1,83 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	hashRegistryLock sync.RWMutex
+	hashRegistry     = map[string]HashFunc{
+		"adler32":    Adler32,
+		"crc32":      Crc32,
+		"crc32c":     Crc32C,
+		"crc64":      Crc64,
+		"crc64ecma":  Crc64Ecma,
+		"fnv1a64":    Fnv1a64,
+		"md5":        Md5,
+		"sha1":       Sha1,
+		"sha256":     Sha256,
+		"sha512":     Sha512,
+		"sha512/256": Sha512_256,
+	}
+)
+
+// RegisterHash registers a HashFunc under a name (case-insensitive), replacing any existing registration.
+func RegisterHash(name string, hf HashFunc) {
+	hashRegistryLock.Lock()
+	defer hashRegistryLock.Unlock()
+	hashRegistry[strings.ToLower(name)] = hf
+}
+
+// GetHash looks up a registered HashFunc by name (case-insensitive).
+func GetHash(name string) (HashFunc, bool) {
+	hashRegistryLock.RLock()
+	defer hashRegistryLock.RUnlock()
+	hf, ok := hashRegistry[strings.ToLower(name)]
+	return hf, ok
+}
+
+// HashNames returns the sorted list of registered hash names.
+func HashNames() []string {
+	hashRegistryLock.RLock()
+	defer hashRegistryLock.RUnlock()
+	names := make([]string, 0, len(hashRegistry))
+	for name := range hashRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	typeRegistryLock sync.RWMutex
+	typeRegistry     = map[reflect.Type]func(v interface{}) []byte{}
+)
+
+// RegisterType registers a function that serializes values of a type to their canonical bytes, e.g. for third-party
+// types that can not implement Checksummer. Checksum hashes the serialized bytes rather than walking such values, and
+// this takes precedence over all other ways of checksumming them. Registering a nil function removes the registration.
+func RegisterType(t reflect.Type, fn func(v interface{}) []byte) {
+	typeRegistryLock.Lock()
+	defer typeRegistryLock.Unlock()
+	if fn == nil {
+		delete(typeRegistry, t)
+	} else {
+		typeRegistry[t] = fn
+	}
+	// registered types are not flattened by WithFlattenEmbedded, so cached struct layouts may be outdated
+	structFieldsCache.Range(func(key, _ interface{}) bool {
+		structFieldsCache.Delete(key)
+		return true
+	})
+}
+
+// typeSerializer returns the function registered for a type via RegisterType, if any.
+func typeSerializer(t reflect.Type) (func(v interface{}) []byte, bool) {
+	typeRegistryLock.RLock()
+	defer typeRegistryLock.RUnlock()
+	fn, ok := typeRegistry[t]
+	return fn, ok
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestGetHash(t *testing.T) {
+	testCases := []struct {
+		name string
+		hf   HashFunc
+	}{
+		{"crc32", Crc32},
+		{"CRC64", Crc64},
+		{"Md5", Md5},
+		{"sha256", Sha256},
+	}
+	input := []byte("input")
+	for _, tc := range testCases {
+		hf, ok := GetHash(tc.name)
+		if !ok {
+			t.Errorf("GetHash(%q): not found", tc.name)
+			continue
+		}
+		if !bytes.Equal(hf(input), tc.hf(input)) {
+			t.Errorf("GetHash(%q): returned another function", tc.name)
+		}
+	}
+	if _, ok := GetHash("nonexistent"); ok {
+		t.Errorf("GetHash(%q): expected not found", "nonexistent")
+	}
+}
+
+func TestRegisterHash(t *testing.T) {
+	var wg sync.WaitGroup
+	for _, name := range []string{"Test-Hash-1", "test-hash-2"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			RegisterHash(name, Md5)
+		}(name)
+	}
+	wg.Wait()
+	names := HashNames()
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("expected sorted names, got %v", names)
+	}
+	for _, name := range []string{"test-hash-1", "test-hash-2"} {
+		if i := sort.SearchStrings(names, name); i >= len(names) || names[i] != name {
+			t.Errorf("expected %q to be registered, got %v", name, names)
+		}
+	}
+}
+
+// simulatedUUID stands for a third-party UUID type: its state is unexported, so walking it hashes nothing.
+type simulatedUUID struct {
+	hi, lo uint64
+}
+
+func TestRegisterType(t *testing.T) {
+	typ := reflect.TypeOf(simulatedUUID{})
+	a, b := simulatedUUID{1, 2}, simulatedUUID{1, 3}
+	if !bytes.Equal(Checksum(Md5, a), Checksum(Md5, b)) {
+		t.Fatalf("expected unregistered values to be walked, ignoring their unexported fields")
+	}
+	RegisterType(typ, func(v interface{}) []byte {
+		u := v.(simulatedUUID)
+		return []byte(fmt.Sprintf("%016x%016x", u.hi, u.lo))
+	})
+	defer RegisterType(typ, nil)
+
+	type record struct{ ID simulatedUUID }
+	testCases := []struct {
+		name string
+		a, b interface{}
+	}{
+		{"bare value", a, b},
+		{"pointer", &a, &b},
+		{"nested in a struct", record{a}, record{b}},
+		{"nested in a slice", []simulatedUUID{a}, []simulatedUUID{b}},
+	}
+	for _, tc := range testCases {
+		if ca, cb := Checksum(Md5, tc.a), Checksum(Md5, tc.b); ca == nil || bytes.Equal(ca, cb) {
+			t.Errorf("%s: expected the serializer to be used, both got %x", tc.name, ca)
+		}
+	}
+	expected := Md5(append([]byte{tagCustom}, "00000000000000010000000000000002"...))
+	if actual := Checksum(Md5, a); !bytes.Equal(actual, expected) {
+		t.Errorf("expected %x, got %x", expected, actual)
+	}
+	RegisterType(typ, nil)
+	if !bytes.Equal(Checksum(Md5, a), Checksum(Md5, b)) {
+		t.Errorf("expected a nil function to remove the registration")
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Result is a self-describing checksum: the digest along with the name of the registered algorithm that produced it,
+// so that stored checksums remain verifiable across a migration to another algorithm.
+//
+// Its text form is "<algorithm>:<lowercase hex digest>", e.g. "sha256:2c26b46b...".
+type Result struct {
+	Algorithm string // registered name of the hash algorithm, see RegisterHash
+	Sum       []byte // the checksum
+}
+
+// ChecksumResult is similar to ChecksumE, but looks up the hash algorithm by its registered name (case-insensitive)
+// and wraps the checksum in a Result.
+func ChecksumResult(name string, v interface{}) (Result, error) {
+	hf, ok := GetHash(name)
+	if !ok {
+		return Result{}, fmt.Errorf("unknown hash algorithm %q", name)
+	}
+	sum, err := ChecksumE(hf, v)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Algorithm: strings.ToLower(name), Sum: sum}, nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r Result) MarshalText() ([]byte, error) {
+	return []byte(r.Algorithm + ":" + hex.EncodeToString(r.Sum)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It fails if the algorithm is not registered, or if the digest
+// does not have the size the algorithm produces.
+func (r *Result) UnmarshalText(text []byte) error {
+	i := bytes.IndexByte(text, ':')
+	if i < 0 {
+		return fmt.Errorf("invalid checksum %q: missing algorithm prefix", text)
+	}
+	name := strings.ToLower(string(text[:i]))
+	hf, ok := GetHash(name)
+	if !ok {
+		return fmt.Errorf("invalid checksum %q: unknown hash algorithm %q", text, name)
+	}
+	sum, err := hex.DecodeString(string(text[i+1:]))
+	if err != nil {
+		return fmt.Errorf("invalid checksum %q: %w", text, err)
+	}
+	if size := DigestSize(hf); len(sum) != size {
+		return fmt.Errorf("invalid checksum %q: %s digests are %d bytes, got %d", text, name, size, len(sum))
+	}
+	r.Algorithm, r.Sum = name, sum
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestResult(t *testing.T) {
+	value := map[string]interface{}{"id": 1, "tags": []string{"a", "b"}}
+	for _, name := range []string{"sha256", "MD5", "sha512/256"} {
+		result, err := ChecksumResult(name, value)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		hf, _ := GetHash(name)
+		if expected := Checksum(hf, value); result.Algorithm != strings.ToLower(name) || !bytes.Equal(result.Sum, expected) {
+			t.Errorf("%s: expected %s:%x, got %s:%x", name, strings.ToLower(name), expected, result.Algorithm, result.Sum)
+		}
+		text, _ := result.MarshalText()
+		if expected := strings.ToLower(name) + ":" + hex.EncodeToString(result.Sum); string(text) != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, text)
+		}
+		var parsed Result
+		if err := parsed.UnmarshalText(text); err != nil {
+			t.Errorf("%s: %s", name, err)
+		} else if parsed.Algorithm != result.Algorithm || !bytes.Equal(parsed.Sum, result.Sum) {
+			t.Errorf("%s: expected %s:%x, got %s:%x", name, result.Algorithm, result.Sum, parsed.Algorithm, parsed.Sum)
+		}
+	}
+	if _, err := ChecksumResult("no-such-hash", value); err == nil {
+		t.Errorf("expected an error for an unknown algorithm")
+	}
+
+	sha256Text := "sha256:" + hex.EncodeToString(Checksum(Sha256, value))
+	for _, text := range []string{
+		strings.Replace(sha256Text, "sha256", "md5", 1), // mismatched algorithm prefix: wrong digest size
+		strings.Replace(sha256Text, "sha256", "no-such-hash", 1),
+		strings.TrimPrefix(sha256Text, "sha256:"),
+		sha256Text + "zz",
+	} {
+		var parsed Result
+		if err := parsed.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("%s: expected a parse error, got %s:%x", text, parsed.Algorithm, parsed.Sum)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/spaolacci/murmur3"
+)
+
+// NewSeeded builds a HashFunc for a seedable non-cryptographic algorithm, identified by its registered name
+// (case-insensitive): distinct seeds give independent hash functions, e.g. for Bloom filters. Supported algorithms:
+//   - "fnv1a64": FNV has no native seed, the 8-byte big-endian seed is hashed before the input
+//   - "murmur3-128": the seed must fit in 32 bits
+//   - "xxh64"
+//
+// It returns an error for other algorithms.
+func NewSeeded(algorithm string, seed uint64) (HashFunc, error) {
+	switch strings.ToLower(algorithm) {
+	case "fnv1a64":
+		prefix := make([]byte, 8)
+		binary.BigEndian.PutUint64(prefix, seed)
+		return func(input []byte) []byte {
+			hf := fnv.New64a()
+			hf.Write(prefix)
+			hf.Write(input)
+			return hf.Sum(nil)
+		}, nil
+	case "murmur3-128":
+		if seed > math.MaxUint32 {
+			return nil, fmt.Errorf("seed %d of algorithm %s does not fit in 32 bits", seed, algorithm)
+		}
+		return func(input []byte) []byte {
+			hf := murmur3.New128WithSeed(uint32(seed))
+			hf.Write(input)
+			return hf.Sum(nil)
+		}, nil
+	case "xxh64":
+		return func(input []byte) []byte {
+			hf := xxhash.NewWithSeed(seed)
+			hf.Write(input)
+			return hf.Sum(nil)
+		}, nil
+	}
+	return nil, fmt.Errorf("algorithm %s does not support seeding", algorithm)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestNewSeeded(t *testing.T) {
+	input := []byte("bloom filter key")
+	for _, algorithm := range []string{"fnv1a64", "murmur3-128", "XXH64"} {
+		seeded1, err1 := NewSeeded(algorithm, 1)
+		seeded2, err2 := NewSeeded(algorithm, 2)
+		again, err3 := NewSeeded(algorithm, 1)
+		if err1 != nil || err2 != nil || err3 != nil {
+			t.Errorf("%s: unexpected errors %v, %v, %v", algorithm, err1, err2, err3)
+			continue
+		}
+		if bytes.Equal(seeded1(input), seeded2(input)) {
+			t.Errorf("%s: expected different seeds to give different digests", algorithm)
+		}
+		if !bytes.Equal(seeded1(input), again(input)) {
+			t.Errorf("%s: expected the same seed to be deterministic", algorithm)
+		}
+	}
+	// seed 0 is the default seed of algorithms that have one
+	if seeded, _ := NewSeeded("xxh64", 0); !bytes.Equal(seeded(input), XXH64(input)) {
+		t.Errorf("xxh64: expected seed 0 to match XXH64")
+	}
+	if seeded, _ := NewSeeded("murmur3-128", 0); !bytes.Equal(seeded(input), Murmur3_128(input)) {
+		t.Errorf("murmur3-128: expected seed 0 to match Murmur3_128")
+	}
+	for _, algorithm := range []string{"sha256", "md5", "unknown"} {
+		if hf, err := NewSeeded(algorithm, 1); hf != nil || err == nil {
+			t.Errorf("%s: expected an error", algorithm)
+		}
+	}
+	if hf, err := NewSeeded("murmur3-128", math.MaxUint32+1); hf != nil || err == nil {
+		t.Errorf("murmur3-128: expected an error for a seed that does not fit in 32 bits")
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import "golang.org/x/crypto/sha3"
+
+// Sha3_256 calculates SHA3-256 hash value of a byte slice (32-byte output).
+func Sha3_256(input []byte) []byte {
+	hf := sha3.New256()
+	hf.Write(input)
+	return hf.Sum(nil)
+}
+
+// Keccak256 calculates legacy Keccak-256 hash value of a byte slice (32-byte output), as used by Ethereum and
+// Solidity's keccak256. It differs from Sha3_256 only by its padding, hence produces different digests.
+func Keccak256(input []byte) []byte {
+	hf := sha3.NewLegacyKeccak256()
+	hf.Write(input)
+	return hf.Sum(nil)
+}
+
+func init() {
+	RegisterHash("sha3-256", Sha3_256)
+	builtinDigestSizes[funcPointer(Sha3_256)] = 32
+	RegisterHash("keccak256", Keccak256)
+	builtinDigestSizes[funcPointer(Keccak256)] = 32
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSha3_256(t *testing.T) {
+	testHashVectors(t, "Sha3_256", Sha3_256, 32, []hashVector{
+		{"", "a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a"},
+		{"abc", "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+	})
+}
+
+func TestKeccak256(t *testing.T) {
+	testHashVectors(t, "Keccak256", Keccak256, 32, []hashVector{
+		{"", "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{"abc", "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+	})
+	for _, input := range []string{"", "abc"} {
+		if bytes.Equal(Keccak256([]byte(input)), Sha3_256([]byte(input))) {
+			t.Errorf("%q: expected Keccak256 to differ from Sha3_256", input)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestDigestSize(t *testing.T) {
+	if size := DigestSize(Md5); size != 16 {
+		t.Errorf("DigestSize(Md5): expected 16, got %d", size)
+	}
+	if size := DigestSize(Crc32); size != 4 {
+		t.Errorf("DigestSize(Crc32): expected 4, got %d", size)
+	}
+	for ptr, expected := range builtinDigestSizes {
+		for _, name := range HashNames() {
+			if hf, _ := GetHash(name); funcPointer(hf) == ptr && len(hf(nil)) != expected {
+				t.Errorf("%s: expected a %d-byte digest, got %d bytes", name, expected, len(hf(nil)))
+			}
+		}
+	}
+	calls := 0
+	custom := func(input []byte) []byte {
+		calls++
+		return Truncate(Sha256, 10)(input)
+	}
+	if size := DigestSize(custom); size != 10 || calls != 1 {
+		t.Errorf("expected a 10-byte digest found with a single call, got %d bytes after %d calls", size, calls)
+	}
+	if size := DigestSize(NewHmacFunc(sha256.New, []byte("key"))); size != sha256.Size {
+		t.Errorf("expected closures to be measured, got %d", size)
+	}
+}
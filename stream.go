@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// Hasher calculates a checksum over data written to it incrementally, e.g. via io.Copy.
+//
+// Hasher hashes raw bytes as-is: it bypasses the typed-value semantics of Checksum (type tags, nil sentinels...), so
+// the result equals calling the corresponding HashFunc on the concatenation of all written data.
+type Hasher struct {
+	h hash.Hash
+}
+
+// NewHasher creates a new Hasher using the specified hash constructor, e.g. md5.New.
+func NewHasher(h func() hash.Hash) *Hasher {
+	return &Hasher{h: h()}
+}
+
+// Write implements io.Writer. It never returns an error.
+func (h *Hasher) Write(p []byte) (int, error) {
+	return h.h.Write(p)
+}
+
+// Sum returns the checksum of all data written so far. It does not change the underlying state.
+func (h *Hasher) Sum() []byte {
+	return h.h.Sum(nil)
+}
+
+// Reset discards all data written so far.
+func (h *Hasher) Reset() {
+	h.h.Reset()
+}
+
+// readChunkSize is the size of the chunks ChecksumReader streams its input in.
+const readChunkSize = 32 * 1024
+
+// ChecksumReader calculates the checksum of all data read from r until EOF, streaming it through the hash in fixed-size
+// chunks so that the input is never loaded into memory as a whole.
+func ChecksumReader(h func() hash.Hash, r io.Reader) ([]byte, error) {
+	hasher := NewHasher(h)
+	if _, err := io.CopyBuffer(hasher, r, make([]byte, readChunkSize)); err != nil {
+		return nil, err
+	}
+	return hasher.Sum(), nil
+}
+
+// ChecksumFile calculates the checksum of a file's content, see ChecksumReader.
+func ChecksumFile(h func() hash.Hash, path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("checksum file %s: %w", path, err)
+	}
+	defer f.Close()
+	result, err := ChecksumReader(h, f)
+	if err != nil {
+		return nil, fmt.Errorf("checksum file %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// TeeHasher is an io.Writer that forwards data to an underlying writer while calculating its checksum, e.g. to
+// fingerprint an HTTP response body as it is being sent. It is the write-side, checksum-aware counterpart of
+// io.TeeReader.
+type TeeHasher struct {
+	w io.Writer
+	h hash.Hash
+}
+
+// NewTeeHasher creates a new TeeHasher forwarding data to w, using the specified hash constructor, e.g. md5.New.
+func NewTeeHasher(w io.Writer, h func() hash.Hash) *TeeHasher {
+	return &TeeHasher{w: w, h: h()}
+}
+
+// Write implements io.Writer. Only the bytes successfully forwarded to the underlying writer are hashed, so that the
+// checksum always matches what the destination received.
+func (t *TeeHasher) Write(p []byte) (int, error) {
+	n, err := t.w.Write(p)
+	t.h.Write(p[:n])
+	return n, err
+}
+
+// Sum returns the checksum of all data forwarded so far. It does not change the underlying state.
+func (t *TeeHasher) Sum() []byte {
+	return t.h.Sum(nil)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestHasher(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	for _, chunkSize := range []int{1, 7, 1024, len(data)} {
+		hasher := NewHasher(sha256.New)
+		for i := 0; i < len(data); i += chunkSize {
+			end := i + chunkSize
+			if end > len(data) {
+				end = len(data)
+			}
+			if n, err := hasher.Write(data[i:end]); n != end-i || err != nil {
+				t.Fatalf("chunk size %d: unexpected write result %d, %v", chunkSize, n, err)
+			}
+		}
+		if actual, expected := hasher.Sum(), Sha256(data); !bytes.Equal(actual, expected) {
+			t.Errorf("chunk size %d: expected %x, got %x", chunkSize, expected, actual)
+		}
+	}
+
+	hasher := NewHasher(md5.New)
+	io.Copy(hasher, bytes.NewReader(data))
+	if !bytes.Equal(hasher.Sum(), hasher.Sum()) {
+		t.Errorf("expected Sum not to change the state")
+	}
+	hasher.Reset()
+	hasher.Write([]byte("abc"))
+	if actual, expected := hasher.Sum(), Md5([]byte("abc")); !bytes.Equal(actual, expected) {
+		t.Errorf("expected Reset to discard written data, got %x instead of %x", actual, expected)
+	}
+}
+
+func TestChecksumReader(t *testing.T) {
+	text := strings.Repeat("some streamed content\n", 10000)
+	actual, err := ChecksumReader(md5.New, strings.NewReader(text))
+	if expected := Md5([]byte(text)); err != nil || !bytes.Equal(actual, expected) {
+		t.Errorf("expected %x, got %x (%v)", expected, actual, err)
+	}
+	readErr := errors.New("read failed")
+	reader := io.MultiReader(strings.NewReader(text), iotest.ErrReader(readErr))
+	if actual, err := ChecksumReader(md5.New, reader); actual != nil || !errors.Is(err, readErr) {
+		t.Errorf("expected the read error to be propagated, got %x, %v", actual, err)
+	}
+}
+
+func BenchmarkChecksumReader(b *testing.B) {
+	data := make([]byte, 16*1024*1024)
+	b.Run("ChecksumReader", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ChecksumReader(md5.New, bytes.NewReader(data))
+		}
+	})
+	b.Run("ReadAll", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			all, _ := io.ReadAll(bytes.NewReader(data))
+			Md5(all)
+		}
+	})
+}
+
+func TestChecksumFile(t *testing.T) {
+	content := []byte("known file content")
+	path := filepath.Join(t.TempDir(), "data.txt")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := ChecksumFile(md5.New, path)
+	if expected := Md5(content); err != nil || !bytes.Equal(actual, expected) {
+		t.Errorf("expected %x, got %x (%v)", expected, actual, err)
+	}
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	actual, err = ChecksumFile(md5.New, missing)
+	if actual != nil || !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), missing) {
+		t.Errorf("expected a not-exist error mentioning the path, got %x, %v", actual, err)
+	}
+}
+
+// shortWriter accepts at most limit bytes, then fails.
+type shortWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if room := w.limit - w.buf.Len(); len(p) > room {
+		w.buf.Write(p[:room])
+		return room, io.ErrShortWrite
+	}
+	return w.buf.Write(p)
+}
+
+func TestTeeHasher(t *testing.T) {
+	data := bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog. "), 100)
+	var dst bytes.Buffer
+	tee := NewTeeHasher(&dst, sha256.New)
+	if n, err := io.Copy(tee, iotest.OneByteReader(bytes.NewReader(data))); err != nil || n != int64(len(data)) {
+		t.Fatalf("expected %d bytes copied, got %d (%v)", len(data), n, err)
+	}
+	if !bytes.Equal(dst.Bytes(), data) {
+		t.Errorf("expected the data to be forwarded unchanged")
+	}
+	if expected, actual := Sha256(data), tee.Sum(); !bytes.Equal(actual, expected) {
+		t.Errorf("expected %x, got %x", expected, actual)
+	}
+	if expected, actual := Sha256(data), tee.Sum(); !bytes.Equal(actual, expected) {
+		t.Errorf("Sum should not change the state: expected %x, got %x", expected, actual)
+	}
+
+	// only the bytes accepted by the destination are hashed
+	short := &shortWriter{limit: 10}
+	tee = NewTeeHasher(short, md5.New)
+	if n, err := tee.Write(data); n != 10 || !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("expected a short write of 10 bytes, got %d (%v)", n, err)
+	}
+	if expected, actual := Md5(data[:10]), tee.Sum(); !bytes.Equal(actual, expected) {
+		t.Errorf("expected %x, got %x", expected, actual)
+	}
+}
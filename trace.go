@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"strings"
+)
+
+// TraceEntry records a hash calculated while checksumming a value, see ChecksumTrace.
+type TraceEntry struct {
+	Path   string // location of the value in the input, e.g. "Items[2].Name"; empty for the top-level value
+	Input  []byte // bytes that were hashed; nil if the checksum was calculated by a Checksummer
+	Digest []byte // resulting checksum
+}
+
+// ChecksumTrace is similar to Checksum, but also returns a trace of the checksums calculated for the value and all
+// values nested inside it, in the order they were calculated. This helps diagnosing why two seemingly equal values
+// have different checksums.
+//
+// The name of a struct field (or key of a map entry) is checksummed right before its value, under the same path.
+func ChecksumTrace(hf HashFunc, v interface{}) ([]byte, []TraceEntry) {
+	w := newWalker(context.Background(), hf, nil)
+	w.tracing = true
+	result, _ := w.checksum(v)
+	return result, w.trace
+}
+
+// enter appends a segment to the path of the value being walked.
+func (w *walker) enter(segment string) {
+	w.path = append(w.path, segment)
+}
+
+// leave removes the last segment appended to the path of the value being walked; it is a no-op if not tracing.
+func (w *walker) leave() {
+	if w.tracing {
+		w.path = w.path[:len(w.path)-1]
+	}
+}
+
+// record adds an entry to the trace for the value being walked; it is a no-op if not tracing.
+func (w *walker) record(input, digest []byte) {
+	if w.tracing {
+		path := strings.TrimPrefix(strings.Join(w.path, ""), ".")
+		w.trace = append(w.trace, TraceEntry{Path: path, Input: input, Digest: digest})
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestChecksumTrace(t *testing.T) {
+	type item struct {
+		ID   int
+		Name string
+	}
+	v := item{1, "n"}
+	result, trace := ChecksumTrace(Md5, v)
+	if !bytes.Equal(result, Checksum(Md5, v)) {
+		t.Errorf("expected %x, got %x", Checksum(Md5, v), result)
+	}
+	if len(trace) == 0 || trace[len(trace)-1].Path != "" || !bytes.Equal(trace[len(trace)-1].Digest, result) {
+		t.Fatalf("expected the top-level checksum to be recorded last, got %+v", trace)
+	}
+	values := map[string]interface{}{"ID": v.ID, "Name": v.Name}
+	for path, value := range values {
+		var entries []TraceEntry
+		for _, entry := range trace {
+			if entry.Path == path {
+				entries = append(entries, entry)
+			}
+		}
+		// the field name, then the field value
+		if len(entries) != 2 {
+			t.Errorf("%s: expected 2 entries, got %+v", path, entries)
+			continue
+		}
+		if !bytes.Equal(entries[0].Input, stringToBytes(path)) {
+			t.Errorf("%s: expected the field name to be hashed first, got %x", path, entries[0].Input)
+		}
+		if last := entries[1]; !bytes.Equal(last.Digest, Checksum(Md5, value)) || !bytes.Equal(last.Digest, Md5(last.Input)) {
+			t.Errorf("%s: expected the field value's checksum, got %+v", path, last)
+		}
+	}
+
+	type order struct {
+		Items []item
+		Index map[string]int
+	}
+	_, trace = ChecksumTrace(Md5, order{Items: []item{{1, "a"}, {2, "b"}}, Index: map[string]int{"k": 1}})
+	paths := map[string]bool{}
+	for _, entry := range trace {
+		paths[entry.Path] = true
+	}
+	for _, path := range []string{"", "Items", "Items[0]", "Items[1].Name", "Index", `Index["k"]`} {
+		if !paths[path] {
+			t.Errorf("expected an entry for path %q, got %v", path, paths)
+		}
+	}
+}
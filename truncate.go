@@ -0,0 +1,36 @@
+package main
+
+import "fmt"
+
+// Truncate builds a HashFunc that returns the first n bytes of hf's digest, e.g. the first 8 bytes of SHA-256 as a
+// compact fingerprint. It panics if n is not positive or exceeds the digest size of hf.
+//
+// Truncating a digest to n bytes reduces its collision resistance to that of an n-byte hash: collisions are expected
+// after about 2^(4n) values (birthday bound), e.g. 2^32 values for n = 8.
+func Truncate(hf HashFunc, n int) HashFunc {
+	if size := DigestSize(hf); n <= 0 || n > size {
+		panic(fmt.Sprintf("checksum: can not truncate a %d-byte digest to %d bytes", size, n))
+	}
+	return func(input []byte) []byte {
+		return hf(input)[:n:n]
+	}
+}
+
+// Fold builds a HashFunc that XOR-folds hf's digest down to n bytes: byte i of the digest is XOR-ed into byte i%n
+// of the result. It panics if n is not positive or exceeds the digest size of hf.
+//
+// For cryptographic hashes, whose output bytes are uniformly distributed, folding and truncating are equally
+// collision-resistant (see Truncate). Folding makes every digest byte contribute to the result though, which is safer
+// for non-cryptographic hashes whose bytes are not uniformly distributed.
+func Fold(hf HashFunc, n int) HashFunc {
+	if size := DigestSize(hf); n <= 0 || n > size {
+		panic(fmt.Sprintf("checksum: can not fold a %d-byte digest to %d bytes", size, n))
+	}
+	return func(input []byte) []byte {
+		result := make([]byte, n)
+		for i, b := range hf(input) {
+			result[i%n] ^= b
+		}
+		return result
+	}
+}
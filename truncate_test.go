@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// expectPanic fails the test if f does not panic.
+func expectPanic(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s: expected a panic", name)
+		}
+	}()
+	f()
+}
+
+func TestTruncate(t *testing.T) {
+	input := []byte("compact fingerprint")
+	full := Sha256(input)
+	for _, n := range []int{8, 16, 32} {
+		actual := Truncate(Sha256, n)(input)
+		if !bytes.Equal(actual, full[:n]) {
+			t.Errorf("Truncate(Sha256, %d): expected %x, got %x", n, full[:n], actual)
+		}
+		if DigestSize(Truncate(Sha256, n)) != n {
+			t.Errorf("Truncate(Sha256, %d): expected a %d-byte digest", n, n)
+		}
+	}
+	// a full capacity would let appending to a truncated digest overwrite the rest of the underlying digest
+	digest := Truncate(Sha256, 8)(input)
+	if len(digest) != 8 || cap(digest) != 8 {
+		t.Errorf("expected a length and capacity of 8, got %d and %d", len(digest), cap(digest))
+	}
+	expectPanic(t, "Truncate(Sha256, 33)", func() { Truncate(Sha256, 33) })
+	expectPanic(t, "Truncate(Sha256, 0)", func() { Truncate(Sha256, 0) })
+}
+
+func TestFold(t *testing.T) {
+	input := []byte("fold me")
+	// reference: Python, XOR-ing byte i of hashlib.sha256(b"fold me").digest() into byte i%8
+	expected := "599671fa1ac83430"
+	fold := Fold(Sha256, 8)
+	if actual := hex.EncodeToString(fold(input)); actual != expected {
+		t.Errorf("Fold(Sha256, 8): expected %s, got %s", expected, actual)
+	}
+	if a, b := fold(input), fold(input); !bytes.Equal(a, b) {
+		t.Errorf("expected a deterministic result, got %x and %x", a, b)
+	}
+	if actual, full := Fold(Sha256, 32)(input), Sha256(input); !bytes.Equal(actual, full) {
+		t.Errorf("expected folding to the digest size to be a no-op, got %x instead of %x", actual, full)
+	}
+	if actual := Checksum(fold, map[string]int{"a": 1}); len(actual) != 8 {
+		t.Errorf("expected an 8-byte checksum, got %x", actual)
+	}
+	expectPanic(t, "Fold(Sha256, 33)", func() { Fold(Sha256, 33) })
+	expectPanic(t, "Fold(Sha256, -1)", func() { Fold(Sha256, -1) })
+}
@@ -0,0 +1,35 @@
+package main
+
+import "crypto/subtle"
+
+// Compare reports whether two checksums are equal, in constant time (for equal-length inputs) to not leak timing
+// information.
+func Compare(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// Verify recomputes the checksum of a value and compares it against the expected checksum in constant time.
+//
+// Verify returns false if the value can not be checksummed.
+func Verify(hf HashFunc, v interface{}, expected []byte) bool {
+	actual, err := ChecksumE(hf, v)
+	if err != nil {
+		return false
+	}
+	return Compare(actual, expected)
+}
+
+// VerifyAny reports whether the checksum of a value matches any of the candidates, e.g. during a migration from one
+// hash algorithm to another. Candidates map names of registered hash functions (see RegisterHash) to the expected
+// checksum for that function; HashFunc values can not be used as map keys as functions are not comparable. Names that
+// are not registered never match.
+//
+// Each comparison is done in constant time.
+func VerifyAny(v interface{}, candidates map[string][]byte) bool {
+	for name, expected := range candidates {
+		if hf, ok := GetHash(name); ok && Verify(hf, v, expected) {
+			return true
+		}
+	}
+	return false
+}
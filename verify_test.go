@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	testCases := []struct {
+		name     string
+		a, b     []byte
+		expected bool
+	}{
+		{"equal", []byte{1, 2, 3}, []byte{1, 2, 3}, true},
+		{"unequal, same length", []byte{1, 2, 3}, []byte{1, 2, 4}, false},
+		{"different lengths", []byte{1, 2, 3}, []byte{1, 2}, false},
+		{"empty", []byte{}, nil, true},
+	}
+	for _, tc := range testCases {
+		if actual := Compare(tc.a, tc.b); actual != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	v := map[string]int{"a": 1}
+	expected := Checksum(Sha256, v)
+	if !Verify(Sha256, v, expected) {
+		t.Errorf("expected the checksum to be verified")
+	}
+	if Verify(Sha256, map[string]int{"a": 2}, expected) {
+		t.Errorf("expected a different value not to be verified")
+	}
+	if Verify(Sha256, v, expected[:len(expected)-1]) {
+		t.Errorf("expected a truncated checksum not to be verified")
+	}
+	if Verify(Sha256, make(chan int), nil) {
+		t.Errorf("expected a value that can not be checksummed not to be verified")
+	}
+}
+
+func TestVerifyAny(t *testing.T) {
+	v := map[string]int{"a": 1}
+	md5Checksum, sha256Checksum := Checksum(Md5, v), Checksum(Sha256, v)
+	stale := Checksum(Md5, map[string]int{"a": 2})
+	testCases := []struct {
+		name       string
+		candidates map[string][]byte
+		expected   bool
+	}{
+		{"only SHA-256 matches", map[string][]byte{"md5": stale, "sha256": sha256Checksum}, true},
+		{"only MD5 matches", map[string][]byte{"md5": md5Checksum, "sha256": stale}, true},
+		{"none matches", map[string][]byte{"md5": stale, "sha256": md5Checksum}, false},
+		{"unregistered name", map[string][]byte{"no-such-hash": md5Checksum}, false},
+		{"no candidates", nil, false},
+	}
+	for _, tc := range testCases {
+		if actual := VerifyAny(v, tc.candidates); actual != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+import "github.com/cespare/xxhash/v2"
+
+// XXH64 calculates xxHash (XXH64, seed 0) hash value of a byte slice (8-byte output).
+//
+// xxHash is one of the fastest hash functions available, but is not cryptographically secure.
+func XXH64(input []byte) []byte {
+	hf := xxhash.New()
+	hf.Write(input)
+	return hf.Sum(nil)
+}
+
+func init() {
+	RegisterHash("xxh64", XXH64)
+	builtinDigestSizes[funcPointer(XXH64)] = 8
+}
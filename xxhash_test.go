@@ -0,0 +1,17 @@
+package main
+
+import "testing"
+
+func TestXXH64(t *testing.T) {
+	testHashVectors(t, "XXH64", XXH64, 8, []hashVector{
+		{"", "ef46db3751d8e999"},
+		{"abc", "44bc2cf5ad770999"},
+	})
+}
+
+func BenchmarkXXH64(b *testing.B) {
+	benchmarkHashFuncs(b, 1024*1024,
+		namedHashFunc{"XXH64", XXH64},
+		namedHashFunc{"Crc64", Crc64},
+	)
+}